@@ -1,19 +1,345 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // time_zone works where the system has no zone database
 
+	"terminal-wpm/internal/achievements"
 	"terminal-wpm/internal/app"
+	"terminal-wpm/internal/config"
+	"terminal-wpm/internal/content"
+	"terminal-wpm/internal/history"
+	"terminal-wpm/internal/stats"
 )
 
+// version is set at build time via -ldflags "-X main.version=...".
+var version = "dev"
+
+const usage = `Usage: typr [command] [flags]
+
+Commands:
+  test          run a typing test (default)
+  history       show recent results; "history export" and "history import"
+                move them to and from CSV, JSON or JSON Lines
+  stats         summarise all saved results
+  pb            list personal bests per test configuration
+  achievements  list unlocked and locked achievements
+  profile       list, rename or delete profiles
+  version       print the version
+
+Run "typr <command> -h" for command flags.
+`
+
 func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// run dispatches to a subcommand. Bare flags (typr --time 60s) are
+// treated as flags for the test command.
+func run(args []string) error {
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && !isHelpOrVersion(args[0])) {
+		return runTest(args)
+	}
+
+	cmd, rest := args[0], args[1:]
+	switch cmd {
+	case "test":
+		return runTest(rest)
+	case "history":
+		return runHistory(rest, os.Stdout)
+	case "stats":
+		return runStats(rest, os.Stdout)
+	case "pb":
+		return runPB(rest, os.Stdout)
+	case "achievements":
+		return runAchievements(rest, os.Stdout)
+	case "profile":
+		return runProfile(rest, os.Stdout)
+	case "version", "--version":
+		fmt.Println("typr", version)
+		return nil
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func isHelpOrVersion(arg string) bool {
+	switch arg {
+	case "-h", "--help", "--version":
+		return true
+	}
+	return false
+}
+
+// runTest layers flags over the loaded settings (defaults, config file,
+// profile, environment) and starts the TUI.
+func runTest(args []string) error {
+	cfg, err := testConfig(profileArg(args), args)
+	if err != nil {
+		return err
+	}
+	if cfg.Profiles, err = config.Profiles(); err != nil {
+		return err
+	}
+	if cfg.Profile != "" && !slices.Contains(cfg.Profiles, cfg.Profile) {
+		cfg.Profiles = append(cfg.Profiles, cfg.Profile) // new, nothing saved yet
+	}
+	// The menu's profile picker reloads the settings with the same flags.
+	cfg.SwitchProfile = func(name string) (app.Config, error) {
+		return testConfig(name, args)
+	}
+	return app.Run(cfg)
+}
+
+// testConfig builds the test configuration for a profile from its
+// settings and the command-line flags.
+func testConfig(profile string, args []string) (app.Config, error) {
+	settings, err := config.LoadProfile(profile)
+	if err != nil {
+		return app.Config{}, err
+	}
+	store, err := history.Open(settings)
+	if err != nil {
+		return app.Config{}, err
+	}
+	unlocks, err := achievements.Open(settings)
+	if err != nil {
+		return app.Config{}, err
+	}
 	cfg := app.Config{
-		Mode: "quote",
+		Mode:        settings.Mode,
+		TimeLimit:   time.Duration(settings.TimeLimit),
+		NoSound:     !settings.Sound,
+		WordCounts:  settings.WordCounts,
+		TimeOptions: settings.TimeLimits(),
+
+		SampleInterval: time.Duration(settings.SampleInterval),
+		AutoIndent:     settings.AutoIndent,
+		PracticeRepeat: settings.PracticeRepeat,
+		History:        store,
+		Goal:           settings.DailyGoal,
+		Calendar:       stats.CalendarOf(settings),
+		Achievements:   unlocks,
+		Keys:           settings.Keys,
+		Profile:        settings.Profile,
 	}
 
-	if err := app.Run(cfg); err != nil {
-		fmt.Fprintln(os.Stderr, "error:", err)
-		os.Exit(1)
+	fs := newFlagSet("test")
+	profileFlag(fs)
+	fs.StringVar(&cfg.Mode, "mode", cfg.Mode, "text mode: "+strings.Join(content.Modes(), ", "))
+	fs.IntVar(&cfg.WordCount, "words", 0, "number of words (skips the menu)")
+	textFile := fs.String("text-file", "", "type this UTF-8 file page by page (--words sets the page length); - reads stdin")
+	codeDir := fs.String("code-dir", "", "type functions and blocks from the source tree in this directory (mode codebase)")
+	wordList := fs.String("wordlist", "", "draw words from this newline-separated list instead of the word bank; - reads stdin")
+	fs.StringVar(&cfg.QuoteLength, "quote-length", "", "quote mode: "+strings.Join(append(content.QuoteLengths(), content.AnyLength), ", ")+" (skips the menu)")
+	fs.Var((*secondsFlag)(&cfg.TimeLimit), "time", "time limit, e.g. 60s or 60; without --words runs a timed test (skips the menu)")
+	fs.Uint64Var(&cfg.Seed, "seed", 0, "random seed for reproducible text (0 = random)")
+	mods := make(map[string]*bool)
+	for _, mod := range content.Modifiers() {
+		mods[mod] = fs.Bool(mod, slices.Contains(settings.Modifiers, mod), "add "+mod+" to generated words")
+	}
+	fs.BoolVar(&cfg.AutoIndent, "auto-indent", cfg.AutoIndent, "skip indentation after a line break in code, as editors do")
+	fs.BoolVar(&cfg.NoSound, "no-sound", cfg.NoSound, "disable key sounds")
+	fs.StringVar(&settings.Theme, "theme", settings.Theme, "color theme: "+strings.Join(config.Themes(), ", "))
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if fs.NArg() > 0 {
+		return cfg, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	if !slices.Contains(config.Themes(), settings.Theme) {
+		return cfg, fmt.Errorf("unknown theme %q (want %s)", settings.Theme, strings.Join(config.Themes(), ", "))
+	}
+	cfg.Colors = settings.Palette()
+	for _, mod := range content.Modifiers() {
+		if *mods[mod] {
+			cfg.Modifiers = append(cfg.Modifiers, mod)
+		}
+	}
+	modeSet := false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "time":
+			cfg.SkipMenu = true
+		case "mode":
+			modeSet = true
+		}
+	})
+	if !slices.Contains(content.Modes(), cfg.Mode) {
+		return cfg, fmt.Errorf("unknown mode %q (want %s)", cfg.Mode, strings.Join(content.Modes(), ", "))
+	}
+	if cfg.WordCount < 0 {
+		return cfg, errors.New("--words must not be negative")
+	}
+	if *textFile != "" || *wordList != "" || *codeDir != "" {
+		if err := customText(&cfg, *textFile, *wordList, *codeDir, modeSet); err != nil {
+			return cfg, err
+		}
+	}
+	if cfg.Mode == "quote" && cfg.WordCount > 0 {
+		return cfg, errors.New("--words does not apply to quote mode; use --quote-length")
+	}
+	if cfg.QuoteLength != "" {
+		lengths := append(content.QuoteLengths(), content.AnyLength)
+		switch {
+		case cfg.Mode != "quote":
+			return cfg, errors.New("--quote-length needs --mode quote")
+		case !slices.Contains(lengths, cfg.QuoteLength):
+			return cfg, fmt.Errorf("unknown quote length %q (want %s)", cfg.QuoteLength, strings.Join(lengths, ", "))
+		}
+	}
+	if cfg.TimeLimit < 0 {
+		return cfg, errors.New("--time must not be negative")
+	}
+	return cfg, nil
+}
+
+// customText sets cfg up to type a text file, draw from a word list or
+// cut snippets from a source tree.
+func customText(cfg *app.Config, textFile, wordList, codeDir string, modeSet bool) error {
+	sources := 0
+	for _, s := range []string{textFile, wordList, codeDir} {
+		if s != "" {
+			sources++
+		}
+	}
+	switch {
+	case sources > 1:
+		return errors.New("use only one of --text-file, --wordlist and --code-dir")
+	case modeSet && codeDir != "" && cfg.Mode != "code":
+		return errors.New("--code-dir only goes with --mode code")
+	case modeSet && codeDir == "":
+		return errors.New("--mode does not apply to --text-file or --wordlist")
+	}
+
+	if codeDir != "" {
+		snippets, err := content.ScanCode(codeDir)
+		if err != nil {
+			return err
+		}
+		cfg.Mode, cfg.Snippets = content.CodebaseMode, snippets
+		return nil
+	}
+
+	if wordList != "" {
+		data, err := readInput(wordList)
+		if err != nil {
+			return err
+		}
+		cfg.Mode = content.WordListMode
+		cfg.WordList, err = content.ReadWordList(bytes.NewReader(data), inputName(wordList))
+		return err
+	}
+	data, err := readInput(textFile)
+	if err != nil {
+		return err
+	}
+	text, err := content.ReadText(bytes.NewReader(data), inputName(textFile))
+	if err != nil {
+		return err
+	}
+	if cfg.WordCount == 0 {
+		cfg.WordCount = content.PageWords
+	}
+	cfg.Mode = content.TextMode
+	cfg.Pages, err = content.Pages(text, cfg.WordCount)
+	return err
+}
+
+// readInput reads a file, or stdin for "-".
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return stdin()
+	}
+	return os.ReadFile(path)
+}
+
+// inputName labels errors about path.
+func inputName(path string) string {
+	if path == "-" {
+		return "stdin"
+	}
+	return path
+}
+
+// stdin is read once, as switching profiles builds the configuration
+// again. The TUI then reads keys from the terminal.
+var stdin = sync.OnceValues(func() ([]byte, error) {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return nil, errors.New("- reads stdin, but stdin is a terminal; pipe the text in, e.g. typr --text-file - < notes.txt")
+	}
+	return io.ReadAll(os.Stdin)
+})
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("typr "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// profileFlag adds --profile to fs. TYPR_PROFILE sets its default.
+func profileFlag(fs *flag.FlagSet) *string {
+	return fs.String("profile", os.Getenv("TYPR_PROFILE"), "profile whose settings and results to use")
+}
+
+// profileArg finds the --profile flag in args before they are parsed, as
+// the profile's settings supply the defaults of the other flags.
+func profileArg(args []string) string {
+	profile := os.Getenv("TYPR_PROFILE")
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "profile" {
+			continue
+		}
+		if hasValue {
+			profile = value
+		} else if i+1 < len(args) {
+			profile = args[i+1]
+		}
+	}
+	return profile
+}
+
+// secondsFlag parses a time.Duration, accepting a bare integer as seconds.
+type secondsFlag time.Duration
+
+func (d *secondsFlag) String() string {
+	return time.Duration(*d).String()
+}
+
+func (d *secondsFlag) Set(s string) error {
+	if n, err := strconv.Atoi(s); err == nil {
+		*d = secondsFlag(time.Duration(n) * time.Second)
+		return nil
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q", s)
 	}
+	*d = secondsFlag(parsed)
+	return nil
 }
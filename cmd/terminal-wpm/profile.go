@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"terminal-wpm/internal/config"
+)
+
+const profileUsage = "usage: typr profile [list | rename OLD NEW | delete NAME --yes]"
+
+// runProfile lists, renames and deletes profiles. Each profile keeps its
+// own history, personal bests and config overrides.
+func runProfile(args []string, w io.Writer) error {
+	cmd := "list"
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+	switch cmd {
+	case "list":
+		return listProfiles(args, w)
+	case "rename":
+		fs := newFlagSet("profile rename")
+		names, err := parseAnywhere(fs, args)
+		if err != nil {
+			return err
+		}
+		if len(names) != 2 {
+			return errors.New(profileUsage)
+		}
+		if err := config.RenameProfile(names[0], names[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Renamed profile %s to %s.\n", names[0], names[1])
+		return nil
+	case "delete":
+		fs := newFlagSet("profile delete")
+		yes := fs.Bool("yes", false, "confirm deleting the profile and all of its results")
+		names, err := parseAnywhere(fs, args)
+		if err != nil {
+			return err
+		}
+		if len(names) != 1 {
+			return errors.New(profileUsage)
+		}
+		if !*yes {
+			return fmt.Errorf("deleting profile %s removes all of its results; pass --yes to confirm", names[0])
+		}
+		if err := config.DeleteProfile(names[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Deleted profile %s.\n", names[0])
+		return nil
+	default:
+		return errors.New(profileUsage)
+	}
+}
+
+// parseAnywhere parses flags before, between and after the positional
+// arguments, which it returns, so "delete NAME --yes" works as documented.
+// The flag package alone stops at the first positional argument.
+func parseAnywhere(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		if n := len(args) - fs.NArg(); n > 0 && args[n-1] == "--" {
+			return append(positional, fs.Args()...), nil // no flags after --
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func listProfiles(args []string, w io.Writer) error {
+	fs := newFlagSet("profile list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	names, err := config.Profiles()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		dir, err := config.ProfileDir(name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%-20s %s\n", name, dir)
+	}
+	return nil
+}
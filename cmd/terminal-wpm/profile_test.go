@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"terminal-wpm/internal/config"
+)
+
+func TestProfileDeleteTakesFlagsAfterName(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("APPDATA", home)
+	t.Setenv("TYPR_CONFIG", "")
+	t.Setenv("TYPR_SYNC_DIR", "")
+	dir, err := config.ProfileDir("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "history.jsonl"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runProfile([]string{"delete", "alice"}, &out); err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Fatalf("expected a request for --yes, got %v", err)
+	}
+	if err := runProfile([]string{"delete", "alice", "--yes"}, &out); err != nil {
+		t.Fatalf("delete NAME --yes: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected the profile to be deleted, got %v", err)
+	}
+	if err := runProfile([]string{"delete", "alice", "bob", "--yes"}, &out); err == nil || err.Error() != profileUsage {
+		t.Fatalf("expected the usage for two names, got %v", err)
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"terminal-wpm/internal/achievements"
+	"terminal-wpm/internal/config"
+	"terminal-wpm/internal/history"
+	"terminal-wpm/internal/stats"
+)
+
+// openHistory opens the profile's history store with the configured
+// retention.
+func openHistory(profile string) (*history.Store, error) {
+	settings, err := config.LoadProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	return history.Open(settings)
+}
+
+// loadHistory reads every record, warning on stderr about lines that
+// could not be read instead of failing.
+func loadHistory(profile string) ([]history.Record, error) {
+	settings, err := config.LoadProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	return loadRecords(settings)
+}
+
+// loadRecords is loadHistory for settings that are already loaded.
+func loadRecords(settings config.Settings) ([]history.Record, error) {
+	store, err := history.Open(settings)
+	if err != nil {
+		return nil, err
+	}
+	records, err := store.Load()
+	var corrupt *history.CorruptError
+	if errors.As(err, &corrupt) {
+		fmt.Fprintln(os.Stderr, "warning:", err)
+		err = nil
+	}
+	return records, err
+}
+
+func runHistory(args []string, w io.Writer) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "export":
+			return runExport(args[1:], w)
+		case "import":
+			return runImport(args[1:], w)
+		}
+	}
+
+	fs := newFlagSet("history")
+	profile := profileFlag(fs)
+	n := fs.Int("n", 10, "number of records to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *n < 0 {
+		return errors.New("-n must not be negative")
+	}
+
+	records, err := loadHistory(*profile)
+	if err != nil {
+		return err
+	}
+	records = records[max(len(records)-*n, 0):]
+	if len(records) == 0 {
+		fmt.Fprintln(w, "No previous sessions yet.")
+		return nil
+	}
+
+	fmt.Fprintf(w, "%-16s %-6s %-9s %6s %6s %7s %7s %9s %5s %s\n", "Date", "Mode", "Test", "WPM", "Raw", "Acc", "RawAcc", "Fixed/Err", "Cons", "Tier")
+	for _, r := range records {
+		fmt.Fprintf(w, "%-16s %-6s %-9s %6.1f %6.1f %6.1f%% %6.1f%% %9s %4.0f%% %s\n",
+			r.Date.Format("2006-01-02 15:04"), r.Mode, r.Label(), r.WPM, r.RawWPM, r.Accuracy, r.RawAccuracy,
+			fmt.Sprintf("%d/%d", r.CorrectedErrors, r.UncorrectedErrors), r.Consistency, r.Tier)
+	}
+	return nil
+}
+
+func runExport(args []string, w io.Writer) error {
+	fs := newFlagSet("history export")
+	profile := profileFlag(fs)
+	format := fs.String("format", "csv", "output format: "+strings.Join(history.Formats(), ", "))
+	var since time.Time
+	fs.Var((*dateFlag)(&since), "since", "only tests on or after this date (YYYY-MM-DD)")
+	mode := fs.String("mode", "", "only this mode")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !slices.Contains(history.Formats(), *format) {
+		return fmt.Errorf("unknown format %q (want %s)", *format, strings.Join(history.Formats(), ", "))
+	}
+
+	records, err := loadHistory(*profile)
+	if err != nil {
+		return err
+	}
+	var out []history.Record
+	for _, r := range records {
+		if (*mode == "" || r.Mode == *mode) && !r.Date.Before(since) {
+			out = append(out, r)
+		}
+	}
+	return history.Export(w, out, *format)
+}
+
+func runImport(args []string, w io.Writer) error {
+	fs := newFlagSet("history import")
+	profile := profileFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: typr history import <file> (csv, json, jsonl or a monkeytype CSV; - reads stdin)")
+	}
+
+	var in io.Reader = os.Stdin
+	if name := fs.Arg(0); name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	records, err := history.ReadRecords(in)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+
+	store, err := openHistory(*profile)
+	if err != nil {
+		return err
+	}
+	added, err := store.Import(records)
+	var corrupt *history.CorruptError
+	if errors.As(err, &corrupt) {
+		fmt.Fprintln(os.Stderr, "warning:", err)
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Imported %d of %d records (%d already in history).\n", added, len(records), len(records)-added)
+	return nil
+}
+
+func runStats(args []string, w io.Writer) error {
+	fs := newFlagSet("stats")
+	profile := profileFlag(fs)
+	var filter stats.Filter
+	var until time.Time
+	fs.StringVar(&filter.Mode, "mode", "", "only this mode (practice drills are left out unless asked for)")
+	fs.Var((*dateFlag)(&filter.Since), "since", "only tests on or after this date (YYYY-MM-DD)")
+	fs.Var((*dateFlag)(&until), "until", "only tests on or before this date (YYYY-MM-DD)")
+	fs.IntVar(&filter.WordCount, "words", 0, "only word tests of this length")
+	fs.Var((*secondsFlag)(&filter.TimeLimit), "time", "only timed tests of this length, e.g. 60s")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !until.IsZero() {
+		filter.Until = until.AddDate(0, 0, 1)
+	}
+
+	settings, err := config.LoadProfile(*profile)
+	if err != nil {
+		return err
+	}
+	records, err := loadRecords(settings)
+	if err != nil {
+		return err
+	}
+	summary := stats.Summarize(filter.Apply(records))
+	// The streak follows every test, whatever the filter.
+	streak := stats.Streaks(records, settings.DailyGoal, stats.CalendarOf(settings), time.Now())
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			stats.Summary
+			Streak stats.Streak `json:"streak"`
+		}{summary, streak})
+	}
+	if summary.Tests == 0 {
+		fmt.Fprintln(w, "No matching sessions.")
+		return nil
+	}
+
+	fmt.Fprintf(w, "Tests:        %d\n", summary.Tests)
+	fmt.Fprintf(w, "Time typing:  %s\n", time.Duration(summary.TimeTyping*float64(time.Second)).Round(time.Second))
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-10s %7s %7s %7s %7s %7s %7s %7s %7s\n", "", "mean", "median", "p10", "p25", "p75", "p90", "min", "max")
+	for _, row := range []struct {
+		name string
+		d    stats.Distribution
+	}{{"WPM", summary.WPM}, {"Accuracy", summary.Accuracy}} {
+		d := row.d
+		fmt.Fprintf(w, "%-10s %7.1f %7.1f %7.1f %7.1f %7.1f %7.1f %7.1f %7.1f\n",
+			row.name, d.Mean, d.Median, d.P10, d.P25, d.P75, d.P90, d.Min, d.Max)
+	}
+	fmt.Fprintln(w)
+	if summary.RawAccuracy > 0 {
+		fmt.Fprintf(w, "Raw accuracy: %.1f%%\n", summary.RawAccuracy)
+	}
+	if summary.Consistency > 0 {
+		fmt.Fprintf(w, "Consistency:  %.0f%%\n", summary.Consistency)
+	}
+	for _, row := range []struct {
+		name string
+		run  *stats.Run
+	}{{"Best", summary.Best}, {"Worst", summary.Worst}} {
+		r := row.run
+		fmt.Fprintf(w, "%-6s        %.1f WPM, %.1f%% (%s • %s, %s)\n",
+			row.name+":", r.WPM, r.Accuracy, r.Mode, r.Test, r.Date.Format("2006-01-02 15:04"))
+	}
+	if t := summary.Trend; t != nil {
+		fmt.Fprintf(w, "Trend:        %+.2f WPM/week (%s to %s)\n", t.WPMPerWeek, t.From.Format("2006-01-02"), t.To.Format("2006-01-02"))
+	}
+	goal := "no goal set"
+	if streak.Goal != (config.Goal{}) {
+		goal = "goal not met yet"
+		if streak.GoalMet {
+			goal = "goal met"
+		}
+	}
+	fmt.Fprintf(w, "Today:        %d tests, %.1f min (%s)\n", streak.Today.Tests, streak.Today.Minutes, goal)
+	fmt.Fprintf(w, "Streak:       %d days (longest %d)\n", streak.Current, streak.Longest)
+	return nil
+}
+
+// dateFlag parses a calendar date in local time.
+type dateFlag time.Time
+
+func (d *dateFlag) String() string {
+	if time.Time(*d).IsZero() {
+		return ""
+	}
+	return time.Time(*d).Format("2006-01-02")
+}
+
+func (d *dateFlag) Set(s string) error {
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid date %q (want YYYY-MM-DD)", s)
+	}
+	*d = dateFlag(t)
+	return nil
+}
+
+func runPB(args []string, w io.Writer) error {
+	fs := newFlagSet("pb")
+	profile := profileFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := openHistory(*profile)
+	if err != nil {
+		return err
+	}
+	bests, err := store.PersonalBests()
+	if err != nil {
+		return err
+	}
+	if len(bests) == 0 {
+		fmt.Fprintln(w, "No personal bests yet.")
+		return nil
+	}
+
+	keys := slices.SortedFunc(maps.Keys(bests), history.CompareKeys)
+	fmt.Fprintf(w, "%-8s %-9s %-9s %-12s %6s %7s %s\n", "Mode", "Test", "Language", "Options", "WPM", "Acc", "Date")
+	for _, k := range keys {
+		r := bests[k]
+		opts := k.Modifiers
+		if opts == "" {
+			opts = "-"
+		}
+		fmt.Fprintf(w, "%-8s %-9s %-9s %-12s %6.1f %6.1f%% %s\n",
+			k.Mode, k.Label(), k.Language, opts, r.WPM, r.Accuracy, r.Date.Format("2006-01-02"))
+	}
+	return nil
+}
+
+func runAchievements(args []string, w io.Writer) error {
+	fs := newFlagSet("achievements")
+	profile := profileFlag(fs)
+	asJSON := fs.Bool("json", false, "print JSON instead of a list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	settings, err := config.LoadProfile(*profile)
+	if err != nil {
+		return err
+	}
+	records, err := loadRecords(settings)
+	if err != nil {
+		return err
+	}
+	store, err := achievements.Open(settings)
+	if err != nil {
+		return err
+	}
+	// Catch up on tests saved elsewhere, e.g. imported or synced.
+	statuses, _, err := store.Update(records, stats.CalendarOf(settings), achievements.Keystrokes{})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+
+	unlocked := 0
+	for _, st := range statuses {
+		if st.Unlock != nil {
+			unlocked++
+			fmt.Fprintf(w, "✓ %-10s  %-18s %s\n", st.Unlock.Date.Format("2006-01-02"), st.Name, st.Description)
+		} else {
+			fmt.Fprintf(w, "  %-10s  %-18s %s (%g/%g)\n", "-", st.Name, st.Description, math.Floor(st.Value), st.Threshold)
+		}
+	}
+	fmt.Fprintf(w, "\n%d of %d unlocked.\n", unlocked, len(statuses))
+	return nil
+}
@@ -0,0 +1,220 @@
+// Package achievements unlocks badges when the history meets declarative
+// rules. The rules live in rules.json; adding a badge needs no code as
+// long as it uses an existing metric.
+package achievements
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"terminal-wpm/internal/content"
+	"terminal-wpm/internal/engine"
+	"terminal-wpm/internal/history"
+	"terminal-wpm/internal/stats"
+)
+
+//go:embed rules.json
+var rulesJSON []byte
+
+// Metrics lists what a rule can measure. tests, words (five characters
+// each) and minutes add up over the matching tests; streak counts days in
+// a row with a finished test and ignores where; the rest take the best
+// single test. clean_run, the most keys in a row typed without a mistake,
+// is read from the keystrokes of the test just finished.
+func Metrics() []string {
+	return []string{"tests", "words", "minutes", "streak", "wpm", "accuracy", "consistency", "burst_wpm", "clean_run"}
+}
+
+// Keystrokes are the key events of the test just finished. The history
+// keeps only totals, so metrics read from keystrokes count that test alone.
+type Keystrokes struct {
+	RecordID string
+	Events   []engine.Event
+}
+
+// cleanRun returns the most keys typed in a row without a mistake.
+// Backspaces neither extend nor break a run; the mistake they fix already
+// ended it.
+func cleanRun(events []engine.Event) int {
+	best, run := 0, 0
+	for _, e := range events {
+		switch {
+		case e.Kind != engine.EventRune:
+		case e.Correct:
+			run++
+			best = max(best, run)
+		default:
+			run = 0
+		}
+	}
+	return best
+}
+
+// Rule describes one achievement: it unlocks when the metric over the
+// tests that pass Where reaches Threshold.
+type Rule struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Metric      string  `json:"metric"`
+	Threshold   float64 `json:"threshold"`
+	Where       Where   `json:"where"`
+}
+
+// Where selects the tests a rule looks at. Only finished tests count, and
+// practice drills only when Mode asks for them. Mode "code" also takes
+// code typed from the user's own tree, see modeCovers.
+type Where struct {
+	Mode          string  `json:"mode,omitempty"`
+	TestType      string  `json:"test_type,omitempty"`
+	MinWPM        float64 `json:"min_wpm,omitempty"`
+	MinAccuracy   float64 `json:"min_accuracy,omitempty"`
+	MaxBackspaces *int    `json:"max_backspaces,omitempty"`
+}
+
+// Match reports whether r passes the conditions.
+func (w Where) Match(r history.Record) bool {
+	switch {
+	case !r.Completed:
+		return false
+	case w.Mode == "" && r.Mode == history.PracticeMode:
+		return false
+	case w.Mode != "" && r.Mode != w.Mode && !slices.Contains(modeCovers[w.Mode], r.Mode):
+		return false
+	case w.TestType != "" && r.TestType != w.TestType:
+		return false
+	case r.WPM < w.MinWPM || r.Accuracy < w.MinAccuracy:
+		return false
+	case w.MaxBackspaces != nil && r.Backspaces > *w.MaxBackspaces:
+		return false
+	}
+	return true
+}
+
+// modeCovers lists the other modes a Where.Mode takes in: code typed with
+// --code-dir is saved as its own mode, but is code all the same.
+var modeCovers = map[string][]string{
+	"code": {content.CodebaseMode},
+}
+
+// Rules returns the built-in rules in display order.
+var Rules = sync.OnceValues(func() ([]Rule, error) {
+	return ParseRules(rulesJSON)
+})
+
+// ParseRules reads a JSON array of rules, rejecting unknown fields and
+// metrics and duplicate IDs.
+func ParseRules(data []byte) ([]Rule, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var rules []Rule
+	if err := dec.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("achievement rules: %w", err)
+	}
+	seen := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		switch {
+		case r.ID == "" || r.Name == "":
+			return nil, fmt.Errorf("achievement rules: every rule needs an id and a name")
+		case seen[r.ID]:
+			return nil, fmt.Errorf("achievement rules: duplicate id %q", r.ID)
+		case !slices.Contains(Metrics(), r.Metric):
+			return nil, fmt.Errorf("achievement rules: %s: unknown metric %q", r.ID, r.Metric)
+		case r.Threshold <= 0:
+			return nil, fmt.Errorf("achievement rules: %s: threshold must be greater than zero", r.ID)
+		}
+		seen[r.ID] = true
+	}
+	return rules, nil
+}
+
+// Unlock records when an achievement was earned and by which test.
+type Unlock struct {
+	ID       string    `json:"id"`
+	Date     time.Time `json:"date"`
+	RecordID string    `json:"record_id,omitempty"`
+}
+
+// Status is a rule with the progress made toward it.
+type Status struct {
+	Rule
+	Value  float64 `json:"value"`            // the metric so far, capped at the threshold
+	Unlock *Unlock `json:"unlock,omitempty"` // nil while locked
+}
+
+// Evaluate replays the records in date order and reports, for every rule,
+// the progress made and the test that first reached the threshold. keys
+// belong to the record with their ID, if any.
+func Evaluate(rules []Rule, records []history.Record, cal stats.Calendar, keys Keystrokes) []Status {
+	records = slices.Clone(records)
+	slices.SortStableFunc(records, func(a, b history.Record) int { return a.Date.Compare(b.Date) })
+
+	out := make([]Status, len(rules))
+	for i, rule := range rules {
+		out[i].Rule = rule
+	}
+	var lastDay time.Time
+	run := 0
+	for _, r := range records {
+		var events []engine.Event
+		if r.ID != "" && r.ID == keys.RecordID {
+			events = keys.Events
+		}
+		if r.Completed {
+			// Days in a row with a finished test.
+			switch day := cal.Day(r.Date); {
+			case day.Equal(lastDay):
+			case day.Equal(lastDay.AddDate(0, 0, 1)):
+				run++
+				lastDay = day
+			default:
+				run = 1
+				lastDay = day
+			}
+		}
+		for i := range out {
+			st := &out[i]
+			if st.Unlock != nil {
+				continue
+			}
+			if st.Metric == "streak" {
+				st.Value = max(st.Value, float64(run))
+			} else if st.Where.Match(r) {
+				st.Value = st.next(r, events)
+			}
+			if st.Value >= st.Threshold {
+				st.Value = st.Threshold
+				st.Unlock = &Unlock{ID: st.ID, Date: r.Date, RecordID: r.ID}
+			}
+		}
+	}
+	return out
+}
+
+// next folds r, with its keystrokes when known, into the metric.
+func (st Status) next(r history.Record, events []engine.Event) float64 {
+	switch st.Metric {
+	case "tests":
+		return st.Value + 1
+	case "words":
+		return st.Value + r.WPM*r.TimeTaken/60
+	case "minutes":
+		return st.Value + r.TimeTaken/60
+	case "wpm":
+		return max(st.Value, r.WPM)
+	case "accuracy":
+		return max(st.Value, r.Accuracy)
+	case "consistency":
+		return max(st.Value, r.Consistency)
+	case "burst_wpm":
+		return max(st.Value, r.BurstWPM)
+	case "clean_run":
+		return max(st.Value, float64(cleanRun(events)))
+	}
+	return st.Value
+}
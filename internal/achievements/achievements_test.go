@@ -0,0 +1,128 @@
+package achievements
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"terminal-wpm/internal/content"
+	"terminal-wpm/internal/engine"
+	"terminal-wpm/internal/history"
+	"terminal-wpm/internal/stats"
+)
+
+func TestBuiltInRulesParse(t *testing.T) {
+	rules, err := Rules()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rules) == 0 {
+		t.Fatalf("expected built-in rules")
+	}
+}
+
+func TestParseRulesRejectsUnknownMetric(t *testing.T) {
+	_, err := ParseRules([]byte(`[{"id": "x", "name": "X", "metric": "speed", "threshold": 1}]`))
+	if err == nil || !strings.Contains(err.Error(), "unknown metric") {
+		t.Fatalf("expected unknown metric error, got %v", err)
+	}
+}
+
+func TestEvaluateDatesUnlocks(t *testing.T) {
+	rules, err := ParseRules([]byte(`[
+		{"id": "perfect-2", "name": "P", "metric": "tests", "threshold": 2, "where": {"min_accuracy": 100}},
+		{"id": "code-words", "name": "C", "metric": "words", "threshold": 50, "where": {"mode": "code"}},
+		{"id": "streak-3", "name": "S", "metric": "streak", "threshold": 3},
+		{"id": "fast", "name": "F", "metric": "wpm", "threshold": 100}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(d int) time.Time { return time.Date(2026, 4, d, 12, 0, 0, 0, time.UTC) }
+	records := []history.Record{
+		{ID: "c", Date: day(3), Mode: "quote", WPM: 60, Accuracy: 100, Completed: true},
+		{ID: "a", Date: day(1), Mode: "code", WPM: 60, Accuracy: 100, TimeTaken: 30, Completed: true},
+		{ID: "b", Date: day(2), Mode: "code", WPM: 60, Accuracy: 100, TimeTaken: 30, Completed: false},
+		{ID: "d", Date: day(4), Mode: "code", WPM: 40, Accuracy: 95, TimeTaken: 60, Completed: true},
+	}
+	statuses := Evaluate(rules, records, stats.Calendar{Location: time.UTC}, Keystrokes{})
+	want := map[string]string{"perfect-2": "c", "code-words": "d", "streak-3": "", "fast": ""}
+	for _, st := range statuses {
+		got := ""
+		if st.Unlock != nil {
+			got = st.Unlock.RecordID
+		}
+		if got != want[st.ID] {
+			t.Fatalf("%s: expected unlock by %q, got %q (%+v)", st.ID, want[st.ID], got, st)
+		}
+	}
+	// The unfinished test on the 2nd breaks the streak after one day.
+	if statuses[2].Value != 2 || statuses[3].Value != 60 {
+		t.Fatalf("unexpected progress: streak %.0f, wpm %.0f", statuses[2].Value, statuses[3].Value)
+	}
+}
+
+func TestCodeRulesCountCodebaseRuns(t *testing.T) {
+	rules, err := ParseRules([]byte(`[{"id": "code-20", "name": "C", "metric": "words", "threshold": 20, "where": {"mode": "code"}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	records := []history.Record{
+		{ID: "a", Date: now, Mode: "words", WPM: 60, TimeTaken: 60, Completed: true},
+		{ID: "b", Date: now, Mode: content.CodebaseMode, WPM: 40, TimeTaken: 30, Completed: true},
+	}
+	st := Evaluate(rules, records, stats.Calendar{}, Keystrokes{})[0]
+	if st.Unlock == nil || st.Unlock.RecordID != "b" {
+		t.Fatalf("expected the codebase run to unlock the code rule, got %+v", st)
+	}
+}
+
+func TestEvaluateReadsKeystrokes(t *testing.T) {
+	rules, err := ParseRules([]byte(`[{"id": "clean-5", "name": "C", "metric": "clean_run", "threshold": 5}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := func(correct bool) engine.Event { return engine.Event{Kind: engine.EventRune, Correct: correct} }
+	backspace := engine.Event{Kind: engine.EventBackspace, Correct: false}
+	// Runs of 3 and 4: the backspace fixing the mistake does not join them.
+	events := []engine.Event{key(true), key(true), key(true), key(false), backspace, key(true), key(true), key(true), key(true)}
+	records := []history.Record{
+		{ID: "a", Date: time.Now(), Mode: "quote", Completed: true},
+		{ID: "b", Date: time.Now(), Mode: "quote", Completed: true},
+	}
+	st := Evaluate(rules, records, stats.Calendar{}, Keystrokes{RecordID: "b", Events: events})[0]
+	if st.Unlock != nil || st.Value != 4 {
+		t.Fatalf("expected a best run of 4 and no unlock, got %+v", st)
+	}
+
+	events = append(events, key(true))
+	st = Evaluate(rules, records, stats.Calendar{}, Keystrokes{RecordID: "b", Events: events})[0]
+	if st.Unlock == nil || st.Unlock.RecordID != "b" {
+		t.Fatalf("expected the run of 5 to unlock on b, got %+v", st)
+	}
+	// Without keystrokes the history alone cannot unlock it.
+	if st := Evaluate(rules, records, stats.Calendar{}, Keystrokes{})[0]; st.Value != 0 {
+		t.Fatalf("expected no progress without keystrokes, got %+v", st)
+	}
+}
+
+func TestStoreAnnouncesUnlocksOnce(t *testing.T) {
+	s := New(t.TempDir())
+	records := []history.Record{{ID: "a", Date: time.Now(), Mode: "quote", WPM: 30, Completed: true}}
+	_, fresh, err := s.Update(records, stats.Calendar{}, Keystrokes{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fresh) != 1 || fresh[0].ID != "first-test" {
+		t.Fatalf("expected first-test to unlock, got %+v", fresh)
+	}
+	// Unlocks stay when the history no longer shows them.
+	statuses, fresh, err := s.Update(nil, stats.Calendar{}, Keystrokes{})
+	if err != nil || len(fresh) != 0 {
+		t.Fatalf("expected nothing new, got %+v, %v", fresh, err)
+	}
+	if statuses[0].ID != "first-test" || statuses[0].Unlock == nil {
+		t.Fatalf("expected the saved unlock to be kept, got %+v", statuses[0])
+	}
+}
@@ -0,0 +1,112 @@
+package achievements
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"terminal-wpm/internal/config"
+	"terminal-wpm/internal/history"
+	"terminal-wpm/internal/stats"
+)
+
+const fileName = "achievements.json" // unlocks, next to the history
+
+// Store keeps a profile's unlocked achievements. Unlocks are never taken
+// back, even when the tests that earned them leave the history.
+type Store struct {
+	path string
+}
+
+// New returns the store in dir.
+func New(dir string) *Store {
+	return &Store{path: filepath.Join(dir, fileName)}
+}
+
+// Open returns the store of the profile in settings.
+func Open(settings config.Settings) (*Store, error) {
+	dir, err := config.ProfileDir(settings.Profile)
+	if err != nil {
+		return nil, err
+	}
+	return New(dir), nil
+}
+
+// Unlocked returns the saved unlocks.
+func (s *Store) Unlocked() ([]Unlock, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var unlocks []Unlock
+	if err := json.Unmarshal(data, &unlocks); err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return unlocks, nil
+}
+
+// Update checks the built-in rules against records, and the keystrokes of
+// the test just finished, and saves what they unlock. It returns every
+// rule's status, with the saved unlock dates, and the statuses of the
+// achievements unlocked for the first time.
+func (s *Store) Update(records []history.Record, cal stats.Calendar, keys Keystrokes) (all, fresh []Status, err error) {
+	rules, err := Rules()
+	if err != nil {
+		return nil, nil, err
+	}
+	saved, err := s.Unlocked()
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[string]Unlock, len(saved))
+	for _, u := range saved {
+		byID[u.ID] = u
+	}
+
+	all = Evaluate(rules, records, cal, keys)
+	for i := range all {
+		st := &all[i]
+		if u, ok := byID[st.ID]; ok {
+			st.Value, st.Unlock = st.Threshold, &u
+		} else if st.Unlock != nil {
+			fresh = append(fresh, *st)
+			saved = append(saved, *st.Unlock)
+		}
+	}
+	if len(fresh) == 0 {
+		return all, nil, nil
+	}
+	if err := s.write(saved); err != nil {
+		return nil, nil, err
+	}
+	return all, fresh, nil
+}
+
+// write atomically replaces the unlocks file.
+func (s *Store) write(unlocks []Unlock) error {
+	data, err := json.MarshalIndent(unlocks, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), fileName+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
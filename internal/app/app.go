@@ -1,15 +1,22 @@
 package app
 
 import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
 	"time"
+	"unicode"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"terminal-wpm/internal/achievements"
+	"terminal-wpm/internal/config"
 	"terminal-wpm/internal/content"
 	"terminal-wpm/internal/engine"
 	"terminal-wpm/internal/history"
 	"terminal-wpm/internal/sound"
+	"terminal-wpm/internal/stats"
 )
 
 // phase tracks which screen the TUI is showing.
@@ -19,28 +26,94 @@ const (
 	phaseMenu   phase = iota // word-count selection
 	phaseTyping              // active typing test
 	phaseDone                // final results
+	phaseStats               // statistics dashboard
 )
 
-// wordOption represents one selectable word-count choice.
-type wordOption struct {
+// testOption represents one selectable test length: a word count, a
+// quote length or, for timed tests, a time limit.
+type testOption struct {
 	label string
 	count int
+	quote string
+	limit time.Duration
 }
 
-var wordOptions = []wordOption{
-	{"30 words", 30},
-	{"60 words", 60},
+// buildTestOptions lists the menu choices. Quote mode offers quote lengths
+// in place of word counts.
+func buildTestOptions(mode string, counts []int, limits []time.Duration) []testOption {
+	if len(counts) == 0 && len(limits) == 0 {
+		def := config.Default()
+		counts = def.WordCounts
+		for _, d := range def.TimeOptions {
+			limits = append(limits, time.Duration(d))
+		}
+	}
+	opts := make([]testOption, 0, len(counts)+len(limits))
+	if mode == "quote" {
+		for _, length := range append(content.QuoteLengths(), content.AnyLength) {
+			opts = append(opts, testOption{label: length + " quote", quote: length})
+		}
+		counts = nil
+	}
+	for _, n := range counts {
+		opts = append(opts, testOption{label: fmt.Sprintf("%d words", n), count: n})
+	}
+	for _, d := range limits {
+		opts = append(opts, testOption{label: fmt.Sprintf("%g seconds", d.Seconds()), limit: d})
+	}
+	return opts
 }
 
+// Config describes a test. When WordCount or QuoteLength is set or
+// SkipMenu is true the menu is skipped and the test starts immediately. A
+// zero WordCount with a TimeLimit runs a timed test over endless text.
 type Config struct {
-	Mode      string
-	TimeLimit time.Duration
-	WordCount int
+	Mode        string
+	TimeLimit   time.Duration
+	WordCount   int
+	QuoteLength string   // quote mode: a length from content.QuoteLengths or content.AnyLength
+	Modifiers   []string // text options for generated words, see content.Modifiers
+
+	Seed     uint64 // 0 picks a random seed
+	NoSound  bool
+	SkipMenu bool
+
+	// Custom text. Pages of a text file are typed in turn, one per test,
+	// and skip the menu; a word list replaces the mode's word bank, and
+	// snippets of a source tree replace it with real code.
+	Pages    []string
+	WordList []string
+	Snippets []content.Snippet
+
+	SampleInterval time.Duration // bucket width for consistency and the chart
+	AutoIndent     bool          // skip indentation after a line break in code
+
+	WordCounts  []int           // menu choices
+	TimeOptions []time.Duration // menu choices for timed tests
+	Colors      config.Colors   // resolved theme palette
+	Keys        config.Keys
+
+	PracticeRepeat int            // times each missed word appears in a practice drill
+	History        *history.Store // where results are saved; nil keeps them in memory only
+	Goal           config.Goal    // daily practice target
+	Calendar       stats.Calendar // how tests are grouped into practice days
+
+	Achievements *achievements.Store // where unlocks are saved; nil turns them off
+
+	Profile  string   // active profile; "" is the default profile
+	Profiles []string // profiles the menu can switch between
+	// SwitchProfile loads the settings of another profile. Nil hides the
+	// profile picker.
+	SwitchProfile func(name string) (Config, error)
 }
 
 func Run(cfg Config) error {
 	// Initialize audio (best-effort; app works without sound).
-	_ = sound.Init()
+	// Without Init the Play* functions are no-ops.
+	if !cfg.NoSound {
+		_ = sound.Init()
+	}
+	applyPalette(cfg.Colors)
 
 	m := newModel(cfg)
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
@@ -54,13 +127,19 @@ func Run(cfg Config) error {
 	return nil
 }
 
-type tickMsg time.Time
+// tickMsg drives the clock of a test. gen tells the tick loops apart: a
+// tick queued before a test ended can land after the next one started,
+// and must not start a second loop.
+type tickMsg struct {
+	at  time.Time
+	gen int
+}
 
 type model struct {
 	cfg       Config
 	phase     phase
+	options   []testOption
 	menuIdx   int // currently highlighted menu option
-	target    string
 	session   *engine.Session
 	now       time.Time
 	width     int
@@ -68,26 +147,59 @@ type model struct {
 	timedOut  bool
 	cancelled bool
 	final     engine.Metrics
+	missed    []engine.MissedWord // words mistyped in the last test
+	practice  bool                // the session drills missed words
+	drill     []string            // words the practice session is built from
+	quote     *content.Quote      // quote of the current test, if any
+	snippet   *content.Snippet    // code snippet of the current test, if any
+	page      int                 // index into cfg.Pages of the current test
+	seed      uint64              // text seed of the current test, reused on restart
+	armed     bool                // the restart key was pressed; Enter confirms
+	tickGen   int                 // generation of the running tick loop
 	history   []history.Record
-	scrollY   int // vertical scroll offset (shared across all views)
+	pb        *history.Record       // personal best before the last test, if any
+	newPB     bool                  // the last test beat pb
+	dash      dashboard             // statistics screen, loaded when opened
+	streak    stats.Streak          // daily goal progress and streak
+	unlocked  []achievements.Status // achievements the last test unlocked
+	scrollY   int                   // vertical scroll offset (shared across all views)
+	rng       *rand.Rand
 	err       error
 }
 
 func newModel(cfg Config) model {
-	return model{
-		cfg:   cfg,
-		phase: phaseMenu,
-		now:   time.Now(),
+	if len(cfg.Keys.Quit) == 0 {
+		cfg.Keys = config.Default().Keys
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
 	}
+	m := model{
+		cfg:     cfg,
+		phase:   phaseMenu,
+		options: buildTestOptions(cfg.Mode, cfg.WordCounts, cfg.TimeOptions),
+		now:     time.Now(),
+		rng:     rand.New(rand.NewPCG(seed, seed)),
+	}
+	m.refreshStreak()
+	if cfg.WordCount > 0 || cfg.QuoteLength != "" || len(cfg.Pages) > 0 || (cfg.SkipMenu && cfg.TimeLimit > 0) {
+		m.page = -1 // newTest turns to the first page
+		m.newTest()
+	}
+	return m
 }
 
 func (m model) Init() tea.Cmd {
+	if m.phase == phaseTyping {
+		return tickCmd(m.tickGen)
+	}
 	return nil // no tick needed during menu
 }
 
-func tickCmd() tea.Cmd {
+func tickCmd(gen int) tea.Cmd {
 	return tea.Tick(80*time.Millisecond, func(t time.Time) tea.Msg {
-		return tickMsg(t)
+		return tickMsg{at: t, gen: gen}
 	})
 }
 
@@ -107,23 +219,156 @@ func errorSoundCmd() tea.Cmd {
 	}
 }
 
-// startTyping generates the text and transitions to the typing phase.
+// newTest starts a test on fresh text with the current settings: the
+// next page of a text file, or new random text.
+func (m *model) newTest() tea.Cmd {
+	m.practice = false
+	m.seed = m.rng.Uint64()
+	if len(m.cfg.Pages) > 0 {
+		m.page = (m.page + 1) % len(m.cfg.Pages)
+	}
+	return m.startTyping()
+}
+
+// startPractice drills the words missed in the last test, each repeated
+// PracticeRepeat times, with no time limit.
+func (m *model) startPractice() tea.Cmd {
+	m.drill = make([]string, len(m.missed))
+	for i, w := range m.missed {
+		m.drill[i] = w.Expected
+	}
+	m.practice = true
+	m.seed = m.rng.Uint64()
+	return m.startTyping()
+}
+
+// startTyping generates the text for m.seed and transitions to the typing
+// phase, so calling it again restarts the test on the same text. Without a
+// word count or quote length the test is timed and the text streams in.
 func (m *model) startTyping() tea.Cmd {
-	chosen := wordOptions[m.menuIdx]
-	m.cfg.WordCount = chosen.count
+	rng := rand.New(rand.NewPCG(m.seed, m.seed))
+	var session *engine.Session
+	m.quote, m.snippet = nil, nil
+	switch {
+	case m.practice:
+		session = engine.NewSession(content.PracticeText(rng, m.drill, m.cfg.PracticeRepeat), 0)
+	case len(m.cfg.Pages) > 0:
+		session = engine.NewSession(m.cfg.Pages[m.page], m.cfg.TimeLimit)
+	case m.cfg.Mode == "quote" && m.cfg.QuoteLength != "":
+		q, err := content.RandomQuote(rng, m.cfg.QuoteLength)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		m.quote = &q
+		session = engine.NewSession(q.Text, m.cfg.TimeLimit)
+	case m.cfg.Snippets != nil && m.cfg.WordCount > 0:
+		snippet, err := content.RandomSnippet(rng, m.cfg.Snippets, m.cfg.WordCount)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		m.snippet = &snippet
+		session = engine.NewSession(snippet.Text, m.cfg.TimeLimit)
+	case m.cfg.Snippets != nil:
+		next, err := content.SnippetStream(rng, m.cfg.Snippets)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		session = engine.NewTimedSession(next, m.cfg.TimeLimit)
+	case m.cfg.WordCount > 0:
+		var text string
+		var err error
+		if m.cfg.WordList != nil {
+			text, err = content.WordsFrom(rng, m.cfg.WordList, m.cfg.WordCount)
+		} else {
+			text, err = content.RandomTextWith(rng, m.cfg.Mode, m.cfg.WordCount)
+		}
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		session = engine.NewSession(content.Modify(rng, text, m.modifiers()), m.cfg.TimeLimit)
+	case m.cfg.WordList != nil:
+		next := content.StreamFrom(rng, m.cfg.WordList)
+		session = engine.NewTimedSession(content.ModifyStream(rng, next, m.modifiers()), m.cfg.TimeLimit)
+	default:
+		next, err := content.WordStream(rng, m.cfg.Mode)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		session = engine.NewTimedSession(content.ModifyStream(rng, next, m.modifiers()), m.cfg.TimeLimit)
+	}
+	return m.begin(session)
+}
 
-	text, err := content.RandomText(m.cfg.Mode, m.cfg.WordCount)
-	if err != nil {
-		m.err = err
+// ownText reports whether the test types a text file, word list or source
+// tree of the user's, whose results do not set personal bests.
+func (m model) ownText() bool {
+	return m.cfg.Pages != nil || m.cfg.WordList != nil || m.cfg.Snippets != nil
+}
+
+// modifiable reports whether the test types generated words, which the
+// text modifiers apply to. Quotes, files and code are typed as written.
+func (m model) modifiable() bool {
+	return m.cfg.Mode != "quote" && m.cfg.Pages == nil && m.cfg.Snippets == nil
+}
+
+// modifiers returns the text modifiers in effect for the next test.
+func (m model) modifiers() []string {
+	if !m.modifiable() {
 		return nil
 	}
+	return m.cfg.Modifiers
+}
+
+// toggleModifier switches a text modifier on or off.
+func (m *model) toggleModifier(mod string) {
+	if i := slices.Index(m.cfg.Modifiers, mod); i >= 0 {
+		m.cfg.Modifiers = slices.Delete(slices.Clone(m.cfg.Modifiers), i, i+1)
+		return
+	}
+	m.cfg.Modifiers = append(slices.Clone(m.cfg.Modifiers), mod)
+}
 
-	m.target = text
-	m.session = engine.NewSession(text, m.cfg.TimeLimit)
+// begin switches to the typing phase with a fresh session.
+func (m *model) begin(session *engine.Session) tea.Cmd {
+	ticking := m.phase == phaseTyping
+	session.SetSampleInterval(m.cfg.SampleInterval)
+	session.SetAutoIndent(m.cfg.AutoIndent)
+	m.session = session
+	m.timedOut, m.cancelled = false, false
+	m.armed = false
 	m.phase = phaseTyping
 	m.scrollY = 0
 	m.now = time.Now()
-	return tickCmd()
+	if ticking {
+		return nil // the running tick loop carries over
+	}
+	m.tickGen++
+	return tickCmd(m.tickGen)
+}
+
+// restart abandons the test in progress, saving it if any key was typed,
+// and starts over on the same text.
+func (m *model) restart() tea.Cmd {
+	if m.phase == phaseTyping && m.session.Started() {
+		m.final = m.session.Snapshot(m.now, false, true)
+		m.saveHistory()
+	}
+	return m.startTyping()
+}
+
+// finish ends the test, scores it, and records it in history.
+func (m *model) finish(timedOut, cancelled bool) {
+	m.timedOut, m.cancelled = timedOut, cancelled
+	m.phase = phaseDone
+	m.scrollY = 0
+	m.final = m.session.Snapshot(m.now, timedOut, cancelled)
+	m.missed = m.session.MissedWords()
+	m.saveHistory()
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -147,19 +392,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case tickMsg:
-		if m.phase != phaseTyping {
-			return m, nil
+		if m.phase != phaseTyping || typed.gen != m.tickGen {
+			return m, nil // stale: the loop ended with its test
 		}
-		m.now = time.Time(typed)
+		m.now = typed.at
 		if m.session.IsTimedOut(m.now) {
-			m.timedOut = true
-			m.phase = phaseDone
-			m.scrollY = 0
-			m.final = m.session.Snapshot(m.now, true, false)
-			m.saveHistory()
+			m.finish(true, false)
 			return m, nil
 		}
-		return m, tickCmd()
+		return m, tickCmd(m.tickGen)
 
 	case tea.KeyMsg:
 		// Handle scroll keys (pgup/pgdn) in all phases.
@@ -184,6 +425,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateTyping(typed)
 		case phaseDone:
 			return m.updateDone(typed)
+		case phaseStats:
+			return m.updateDashboard(typed)
 		}
 	}
 	return m, nil
@@ -192,43 +435,99 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // --- menu phase input ---
 
 func (m model) updateMenu(key tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch key.String() {
-	case "ctrl+c":
+	keys := m.cfg.Keys
+	switch k := key.String(); {
+	case k == "ctrl+c" || keys.Quit.Has(k):
 		return m, tea.Quit
-	case "up", "k":
+	case keys.Up.Has(k):
 		if m.menuIdx > 0 {
 			m.menuIdx--
 		}
-	case "down", "j":
-		if m.menuIdx < len(wordOptions)-1 {
+	case keys.Down.Has(k):
+		if m.menuIdx < len(m.options)-1 {
 			m.menuIdx++
 		}
-	case "enter", " ":
-		cmd := m.startTyping()
+	case keys.Profile.Has(k) && m.cfg.SwitchProfile != nil && len(m.cfg.Profiles) > 1:
+		m.switchProfile()
+	case len(k) == 1 && k >= "1" && int(k[0]-'1') < len(content.Modifiers()) && m.modifiable():
+		m.toggleModifier(content.Modifiers()[k[0]-'1'])
+	case keys.Stats.Has(k):
+		m.dash = loadDashboard(m.cfg.History)
+		m.phase = phaseStats
+		m.scrollY = 0
+	case keys.Start.Has(k):
+		opt := m.options[m.menuIdx]
+		m.cfg.WordCount, m.cfg.QuoteLength = opt.count, opt.quote
+		if opt.limit > 0 {
+			m.cfg.TimeLimit = opt.limit
+		}
+		cmd := m.newTest()
 		return m, cmd
 	}
 	return m, nil
 }
 
+// switchProfile moves the menu to the next profile, taking over its
+// settings, palette and history.
+func (m *model) switchProfile() {
+	next := 0
+	for i, name := range m.cfg.Profiles {
+		if name == m.profileName() {
+			next = (i + 1) % len(m.cfg.Profiles)
+		}
+	}
+	cfg, err := m.cfg.SwitchProfile(m.cfg.Profiles[next])
+	if err != nil {
+		m.err = err
+		return
+	}
+	cfg.Profiles, cfg.SwitchProfile = m.cfg.Profiles, m.cfg.SwitchProfile
+	if len(cfg.Keys.Quit) == 0 {
+		cfg.Keys = config.Default().Keys
+	}
+	m.cfg = cfg
+	m.options = buildTestOptions(cfg.Mode, cfg.WordCounts, cfg.TimeOptions)
+	m.menuIdx = min(m.menuIdx, len(m.options)-1)
+	m.history, m.missed, m.pb, m.unlocked = nil, nil, nil, nil
+	m.refreshStreak()
+	applyPalette(cfg.Colors)
+}
+
+// profileName returns the display name of the active profile.
+func (m model) profileName() string {
+	if m.cfg.Profile == "" {
+		return config.DefaultProfile
+	}
+	return m.cfg.Profile
+}
+
 // --- typing phase input ---
 
 func (m model) updateTyping(key tea.KeyMsg) (tea.Model, tea.Cmd) {
 	m.now = time.Now()
-	switch key.String() {
-	case "ctrl+c":
-		m.cancelled = true
-		m.phase = phaseDone
-		m.scrollY = 0
-		m.final = m.session.Snapshot(m.now, false, true)
-		m.saveHistory()
+	armed := m.armed
+	m.armed = false
+	switch k := key.String(); {
+	case k == "ctrl+c" || m.cfg.Keys.Stop.Has(k):
+		m.finish(false, true)
+		return m, nil
+	case m.restartKeys().Has(k):
+		m.armed = true
 		return m, nil
-	case "backspace", "ctrl+h":
-		m.session.Backspace()
+	case armed && k == "enter":
+		cmd := m.restart()
+		return m, cmd
+	case k == "backspace" || k == "ctrl+h":
+		m.session.Backspace(m.now)
+	case k == "enter" && m.session.Multiline():
+		m.session.ApplyRune('\n', m.now)
+	case k == "tab" && m.session.Multiline():
+		m.session.ApplyRune('\t', m.now)
 	default:
 		runes := key.Runes
 		if len(runes) == 1 {
 			r := runes[0]
-			if r >= 32 && r <= 126 {
+			if unicode.IsPrint(r) { // custom text may hold any letter
 				m.session.ApplyRune(r, m.now)
 			}
 		}
@@ -237,33 +536,49 @@ func (m model) updateTyping(key tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Check completion/timeout BEFORE deciding which sound to play,
 	// so the test finishes immediately when the last character is typed.
 	if m.session.IsCompleted() {
-		m.phase = phaseDone
-		m.scrollY = 0
-		m.final = m.session.Snapshot(m.now, false, false)
-		m.saveHistory()
+		m.finish(false, false)
 		return m, clickCmd()
 	}
-	if m.cfg.TimeLimit > 0 && m.session.IsTimedOut(m.now) {
-		m.timedOut = true
-		m.phase = phaseDone
-		m.scrollY = 0
-		m.final = m.session.Snapshot(m.now, true, false)
-		m.saveHistory()
+	if m.session.TimeLimit() > 0 && m.session.IsTimedOut(m.now) {
+		m.finish(true, false)
 	}
 	return m, clickCmd()
 }
 
+// restartKeys returns the restart binding of the typing screen. Code and
+// other multi-line text type Enter and Tab, so they do not restart it.
+func (m model) restartKeys() config.Binding {
+	if m.session != nil && m.session.Multiline() {
+		return m.cfg.Keys.Restart.Without("enter", "tab")
+	}
+	return m.cfg.Keys.Restart
+}
+
 // --- done phase input ---
 
 func (m model) updateDone(key tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch key.String() {
-	case "enter", "q", "esc", "ctrl+c":
+	keys := m.cfg.Keys
+	armed := m.armed
+	m.armed = false
+	switch k := key.String(); {
+	case k == "ctrl+c" || keys.Quit.Has(k):
 		return m, tea.Quit
-	case "up", "k":
+	case keys.Restart.Has(k):
+		m.armed = true
+	case armed && k == "enter":
+		cmd := m.restart()
+		return m, cmd
+	case keys.Next.Has(k):
+		cmd := m.newTest()
+		return m, cmd
+	case keys.Practice.Has(k) && len(m.missed) > 0:
+		cmd := m.startPractice()
+		return m, cmd
+	case keys.Up.Has(k):
 		if m.scrollY > 0 {
 			m.scrollY--
 		}
-	case "down", "j":
+	case keys.Down.Has(k):
 		m.scrollY++
 	}
 	return m, nil
@@ -271,21 +586,87 @@ func (m model) updateDone(key tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // saveHistory persists the current result and loads recent records for display.
 func (m *model) saveHistory() {
-	tier := performanceTier(m.final.WPM)
+	tier := history.Tier(m.final.WPM)
+	mode, wordCount, quoteLength, mods := m.cfg.Mode, m.cfg.WordCount, "", m.modifiers()
+	switch {
+	case m.practice:
+		mode, wordCount, mods = history.PracticeMode, m.final.TotalWords, nil
+	case m.quote != nil:
+		wordCount, quoteLength = m.final.TotalWords, m.quote.Length()
+	case m.ownText():
+		wordCount = m.final.TotalWords // pages and snippets may run short
+	}
+	language := content.Language
+	if m.ownText() {
+		language = "" // unknown for the user's own text
+	}
 	rec := history.Record{
-		Date:      time.Now(),
-		Mode:      m.cfg.Mode,
-		WordCount: m.cfg.WordCount,
-		WPM:       m.final.WPM,
-		RawWPM:    m.final.RawWPM,
-		Accuracy:  m.final.Accuracy,
-		Errors:    m.final.Errors,
+		Date:        time.Now(),
+		Mode:        mode,
+		TestType:    string(m.final.Kind),
+		WordCount:   wordCount,
+		QuoteLength: quoteLength,
+		TimeLimit:   m.final.TimeLimit.Seconds(),
+		Language:    language,
+		Modifiers:   slices.Clone(mods),
+		WPM:         m.final.WPM,
+		RawWPM:      m.final.RawWPM,
+		Accuracy:    m.final.Accuracy,
+		Errors:      m.final.Errors,
+
+		RawAccuracy:       m.final.RawAccuracy,
+		CorrectedErrors:   m.final.CorrectedErrors,
+		UncorrectedErrors: m.final.UncorrectedErrors,
+		Backspaces:        m.final.Backspaces,
+		Consistency:       m.final.Consistency,
+		BurstWPM:          m.final.BurstWPM,
+
 		TimeTaken: m.final.TimeTaken.Seconds(),
 		Completed: m.final.Completed,
 		Tier:      tier,
+		Samples:   historySamples(m.final.Samples),
+	}
+	if m.cfg.History == nil {
+		return
+	}
+	m.pb, m.newPB = nil, false
+	if best, ok := m.cfg.History.PersonalBest(rec.Key()); ok {
+		m.pb = &best
+	}
+	if rec.CountsForPB() {
+		m.newPB = m.pb == nil || rec.WPM > m.pb.WPM
+	}
+	// The ID ties the session's keystrokes to the record for achievements.
+	rec.ID = history.NewID()
+	_ = m.cfg.History.Save(rec) // best-effort; don't block on save errors
+	m.history = m.cfg.History.Recent(5)
+	records := m.refreshStreak()
+	m.unlocked = nil
+	if m.cfg.Achievements != nil {
+		keys := achievements.Keystrokes{RecordID: rec.ID, Events: m.session.Events()}
+		_, m.unlocked, _ = m.cfg.Achievements.Update(records, m.cfg.Calendar, keys) // best-effort, like saving
+	}
+}
+
+// historySamples converts the speed series for saving.
+func historySamples(samples []engine.Sample) []history.Sample {
+	out := make([]history.Sample, 0, len(samples))
+	for _, s := range samples {
+		out = append(out, history.Sample{At: s.At.Seconds(), WPM: s.WPM, RawWPM: s.RawWPM, Errors: s.Errors})
+	}
+	return out
+}
+
+// refreshStreak recounts the daily goal and streak from the saved history
+// and returns the records it read.
+func (m *model) refreshStreak() []history.Record {
+	m.streak = stats.Streak{Goal: m.cfg.Goal}
+	if m.cfg.History == nil {
+		return nil
 	}
-	_ = history.Save(rec) // best-effort; don't block on save errors
-	m.history = history.Recent(5)
+	records, _ := m.cfg.History.Load() // unreadable lines are reported by typr history
+	m.streak = stats.Streaks(records, m.cfg.Goal, m.cfg.Calendar, time.Now())
+	return records
 }
 
 func (m model) View() string {
@@ -301,6 +682,8 @@ func (m model) View() string {
 		return m.viewMenu()
 	case phaseDone:
 		return m.viewSummary()
+	case phaseStats:
+		return m.viewDashboard()
 	default:
 		return m.viewLive()
 	}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// press returns the key message for a key name or typed text.
+func press(k string) tea.KeyMsg {
+	switch k {
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "ctrl+c":
+		return tea.KeyMsg{Type: tea.KeyCtrlC}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+func TestRestartKeepsOneTickLoop(t *testing.T) {
+	m := newModel(Config{Mode: "words", WordCount: 10, Seed: 1, NoSound: true})
+	step := func(k string) tea.Cmd {
+		t.Helper()
+		nm, cmd := m.Update(press(k))
+		m = nm.(model)
+		return cmd
+	}
+	tick := func(gen int) tea.Cmd {
+		t.Helper()
+		nm, cmd := m.Update(tickMsg{at: m.now.Add(time.Second), gen: gen})
+		m = nm.(model)
+		return cmd
+	}
+	if m.phase != phaseTyping {
+		t.Fatalf("expected the test to start, got phase %v", m.phase)
+	}
+	first := m.tickGen
+	text := string(m.session.Target())
+
+	// Restarting mid-test keeps the running loop instead of starting another.
+	step(text[:1])
+	step("tab")
+	if cmd := step("enter"); cmd != nil {
+		t.Fatal("expected restarting mid-test not to start a second tick loop")
+	}
+	if m.phase != phaseTyping || m.session.Started() || m.tickGen != first {
+		t.Fatalf("expected a fresh test on the same loop, got phase %v, gen %d (was %d)", m.phase, m.tickGen, first)
+	}
+	if tick(first) == nil {
+		t.Fatal("expected the running loop to keep ticking after a restart")
+	}
+
+	// The next test starts a new loop; a tick queued by the old one is dropped.
+	step("ctrl+c")
+	if m.phase != phaseDone {
+		t.Fatalf("expected the test to end, got phase %v", m.phase)
+	}
+	if step("enter") == nil || m.phase != phaseTyping {
+		t.Fatalf("expected the next test to start a tick loop, got phase %v", m.phase)
+	}
+	if m.tickGen == first {
+		t.Fatal("expected the next test to start a new tick generation")
+	}
+	at := m.now
+	if tick(first) != nil {
+		t.Fatal("expected a tick from the old loop to end it")
+	}
+	if !m.now.Equal(at) {
+		t.Fatal("expected a tick from the old loop to leave the clock alone")
+	}
+	if tick(m.tickGen) == nil {
+		t.Fatal("expected the current loop to keep ticking")
+	}
+}
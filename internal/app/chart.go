@@ -0,0 +1,193 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"terminal-wpm/internal/engine"
+)
+
+const (
+	chartRows     = 8  // text rows of plot area; each holds 4 braille dots
+	chartMinWidth = 32 // below this the chart, and its legend, fall back to a sparkline
+)
+
+// brailleBits maps a dot at (x%2, y%4) inside a cell to its bit in the
+// Unicode braille block (U+2800).
+var brailleBits = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// renderChart plots net and raw WPM over time with error markers under
+// the x-axis, in at most width cells. Narrow terminals get a one-line
+// sparkline of net WPM instead, and very narrow ones nothing.
+func renderChart(samples []engine.Sample, width int) string {
+	if len(samples) < 2 || width < len("WPM ")+4 {
+		return ""
+	}
+	if width < chartMinWidth {
+		return renderSparkline(samples, width)
+	}
+
+	top := 0.0
+	for _, s := range samples {
+		top = max(top, s.WPM, s.RawWPM)
+	}
+	top = math.Max(10, math.Ceil(top/10)*10)
+
+	// The y-axis labels are as wide as the top one, at least four cells,
+	// followed by " ┤".
+	digits := max(4, len(fmt.Sprintf("%.0f", top)))
+	axis := digits + 2
+	cols := width - axis
+	dotsX, dotsY := cols*2, chartRows*4
+
+	net := newDotGrid(cols)
+	raw := newDotGrid(cols)
+	toDot := func(i int, wpm float64) (int, int) {
+		x := i * (dotsX - 1) / (len(samples) - 1)
+		y := dotsY - 1 - int(math.Round(wpm/top*float64(dotsY-1)))
+		return x, y
+	}
+	for i := 1; i < len(samples); i++ {
+		x0, y0 := toDot(i-1, samples[i-1].RawWPM)
+		x1, y1 := toDot(i, samples[i].RawWPM)
+		raw.line(x0, y0, x1, y1)
+		x0, y0 = toDot(i-1, samples[i-1].WPM)
+		x1, y1 = toDot(i, samples[i].WPM)
+		net.line(x0, y0, x1, y1)
+	}
+
+	var rows []string
+	for row := 0; row < chartRows; row++ {
+		label := strings.Repeat(" ", digits)
+		switch row {
+		case 0:
+			label = fmt.Sprintf("%*.0f", digits, top)
+		case chartRows / 2:
+			label = fmt.Sprintf("%*.0f", digits, top/2)
+		}
+		var b strings.Builder
+		b.WriteString(hintStyle.Render(label + " ┤"))
+		for col := 0; col < cols; col++ {
+			n, r := net.cell(col, row), raw.cell(col, row)
+			switch {
+			case n != 0:
+				b.WriteString(chartNetStyle.Render(string(0x2800 + (n | r))))
+			case r != 0:
+				b.WriteString(chartRawStyle.Render(string(0x2800 + r)))
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		rows = append(rows, b.String())
+	}
+
+	// Error markers line up with the samples they belong to.
+	marks := []rune(strings.Repeat(" ", cols))
+	for i, s := range samples {
+		if s.Errors > 0 {
+			x, _ := toDot(i, 0)
+			marks[x/2] = '✗'
+		}
+	}
+	rows = append(rows, hintStyle.Render(fmt.Sprintf("%*s └", digits, "0")+strings.Repeat("─", cols)))
+	rows = append(rows, strings.Repeat(" ", axis)+wrongStyle.Render(string(marks)))
+
+	end := formatDuration(samples[len(samples)-1].At)
+	times := fmt.Sprintf("%-*s%s", cols-len(end), "00:00", end)
+	rows = append(rows, strings.Repeat(" ", axis)+hintStyle.Render(times))
+	rows = append(rows, chartNetStyle.Render("⣀⣀ net WPM")+"  "+chartRawStyle.Render("⣀⣀ raw WPM")+"  "+wrongStyle.Render("✗ errors"))
+	return strings.Join(rows, "\n")
+}
+
+// renderSparkline draws net WPM as block characters, one per sample,
+// keeping the most recent samples that fit.
+func renderSparkline(samples []engine.Sample, width int) string {
+	label := "WPM "
+	n := min(len(samples), width-len(label))
+	values := make([]float64, 0, n)
+	for _, s := range samples[len(samples)-n:] {
+		values = append(values, s.WPM)
+	}
+	return hintStyle.Render(label) + chartNetStyle.Render(sparkline(values, 0))
+}
+
+// sparkline draws values as block characters, scaled from floor up to the
+// largest value.
+func sparkline(values []float64, floor float64) string {
+	top := floor
+	for _, v := range values {
+		top = max(top, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		idx := 0
+		if top > floor {
+			idx = int(max(v-floor, 0) / (top - floor) * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[idx])
+	}
+	return b.String()
+}
+
+// dotGrid is a canvas of braille dots, 2 wide and 4 tall per cell.
+type dotGrid struct {
+	cols  int
+	cells []rune
+}
+
+func newDotGrid(cols int) *dotGrid {
+	return &dotGrid{cols: cols, cells: make([]rune, cols*chartRows)}
+}
+
+func (g *dotGrid) set(x, y int) {
+	col, row := x/2, y/4
+	if col < 0 || col >= g.cols || row < 0 || row >= chartRows {
+		return
+	}
+	g.cells[row*g.cols+col] |= brailleBits[x%2][y%4]
+}
+
+func (g *dotGrid) cell(col, row int) rune {
+	return g.cells[row*g.cols+col]
+}
+
+// line draws from (x0, y0) to (x1, y1) with Bresenham's algorithm.
+func (g *dotGrid) line(x0, y0, x1, y1 int) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		g.set(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
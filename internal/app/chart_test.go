@@ -0,0 +1,65 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"terminal-wpm/internal/engine"
+)
+
+func TestRenderChartFitsWidth(t *testing.T) {
+	samples := []engine.Sample{
+		{At: time.Second, WPM: 40, RawWPM: 48, Errors: 1},
+		{At: 2 * time.Second, WPM: 55, RawWPM: 60},
+		{At: 3 * time.Second, WPM: 1100, RawWPM: 1200}, // five-digit labels
+	}
+	for _, width := range []int{chartMinWidth, 40, 80} {
+		for _, series := range [][]engine.Sample{samples[:2], samples} {
+			chart := renderChart(series, width)
+			if chart == "" {
+				t.Fatalf("width %d: expected a chart", width)
+			}
+			for i, row := range strings.Split(chart, "\n") {
+				if w := lipgloss.Width(row); w > width {
+					t.Errorf("width %d: row %d is %d cells: %q", width, i, w, row)
+				}
+			}
+		}
+	}
+}
+
+func TestRenderChartNarrowFallback(t *testing.T) {
+	samples := []engine.Sample{{At: time.Second, WPM: 40}, {At: 2 * time.Second, WPM: 60}}
+	if got := renderChart(samples, chartMinWidth-1); got != "WPM ▅█" {
+		t.Fatalf("expected a sparkline, got %q", got)
+	}
+	if got := renderChart(samples, 7); got != "" {
+		t.Fatalf("expected nothing when too narrow, got %q", got)
+	}
+	if got := renderChart(samples[:1], 80); got != "" {
+		t.Fatalf("expected nothing for a single sample, got %q", got)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		values []float64
+		floor  float64
+		want   string
+	}{
+		{"empty", nil, 0, ""},
+		{"scaled", []float64{0, 50, 100}, 0, "▁▄█"},
+		{"flat at the floor", []float64{40, 40}, 40, "▁▁"},
+		{"flat above the floor", []float64{40, 40}, 0, "██"},
+		{"all below the floor", []float64{10, 20}, 50, "▁▁"},
+		{"clamped to the floor", []float64{90, 100, 95}, 95, "▁█▁"},
+	} {
+		if got := sparkline(tc.values, tc.floor); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
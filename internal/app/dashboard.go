@@ -0,0 +1,331 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"terminal-wpm/internal/history"
+	"terminal-wpm/internal/stats"
+)
+
+const (
+	dashboardRuns = 10 // tests per side when comparing recent accuracy
+	heatmapWeeks  = 26 // columns of the practice calendar
+)
+
+// heatLevels shades a calendar day from no tests to the busiest day.
+var heatLevels = []rune("·░▒▓█")
+
+// dashboard is the statistics screen: every saved record and the personal
+// bests, loaded when the screen opens and narrowed by a typed filter.
+type dashboard struct {
+	records []history.Record
+	bests   map[history.Key]history.Record
+	now     time.Time
+	query   string // space-separated terms a record must all contain
+	editing bool   // keys go to the query
+	warning string // why some or all results are missing
+}
+
+// loadDashboard reads the history for the statistics screen.
+func loadDashboard(store *history.Store) dashboard {
+	d := dashboard{now: time.Now()}
+	if store == nil {
+		d.warning = "History is off, so there is nothing to show."
+		return d
+	}
+	records, err := store.Load()
+	var corrupt *history.CorruptError
+	if err != nil && !errors.As(err, &corrupt) {
+		d.warning = err.Error()
+		return d
+	} else if err != nil {
+		d.warning = err.Error()
+	}
+	d.records = records
+	if d.bests, err = store.PersonalBests(); err != nil {
+		d.warning = err.Error()
+	}
+	return d
+}
+
+// filtered returns the records matching the query, oldest first.
+func (d dashboard) filtered() []history.Record {
+	terms := strings.Fields(strings.ToLower(d.query))
+	if len(terms) == 0 {
+		return d.records
+	}
+	var out []history.Record
+	for _, r := range d.records {
+		if matchesTerms(r, terms) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// matchesTerms reports whether every term appears in the record's mode,
+// length, language, modifiers, tier or date.
+func matchesTerms(r history.Record, terms []string) bool {
+	text := strings.ToLower(strings.Join([]string{
+		r.Mode, r.Label(), r.TestType, r.Language, strings.Join(r.Modifiers, "+"), r.Tier,
+		r.Date.Format("2006-01-02"),
+	}, " "))
+	for _, t := range terms {
+		if !strings.Contains(text, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// --- dashboard phase input ---
+
+func (m model) updateDashboard(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	k := key.String()
+	if k == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if m.dash.editing {
+		switch {
+		case k == "enter" || k == "esc":
+			m.dash.editing = false
+		case k == "backspace" || k == "ctrl+h":
+			if q := []rune(m.dash.query); len(q) > 0 {
+				m.dash.query = string(q[:len(q)-1])
+			}
+		case key.Type == tea.KeyRunes || key.Type == tea.KeySpace:
+			m.dash.query += string(key.Runes)
+		}
+		m.scrollY = 0
+		return m, nil
+	}
+
+	keys := m.cfg.Keys
+	switch {
+	case k == "/":
+		m.dash.editing = true
+	case k == "esc" && m.dash.query != "":
+		m.dash.query = ""
+		m.scrollY = 0
+	case keys.Quit.Has(k) || keys.Stats.Has(k):
+		m.phase = phaseMenu
+		m.scrollY = 0
+	case keys.Up.Has(k):
+		if m.scrollY > 0 {
+			m.scrollY--
+		}
+	case keys.Down.Has(k):
+		m.scrollY++
+	}
+	return m, nil
+}
+
+// --- dashboard rendering ---
+
+func (m model) viewDashboard() string {
+	d := m.dash
+	records := d.filtered()
+	runs := stats.Filter{}.Apply(records) // practice drills skew the charts
+	if strings.Contains(strings.ToLower(d.query), history.PracticeMode) {
+		runs = records
+	}
+
+	title := "Statistics"
+	if m.cfg.Profile != "" {
+		title += " • " + m.cfg.Profile
+	}
+	head := []string{titleStyle.Render(title), ""}
+	switch {
+	case d.editing:
+		head = append(head, "Filter: "+d.query+"█")
+	case d.query != "":
+		head = append(head, "Filter: "+d.query)
+	}
+	summary := stats.Summarize(runs)
+	if summary.Tests > 0 {
+		head = append(head,
+			fmt.Sprintf("Tests: %d • Time typing: %s", summary.Tests,
+				time.Duration(summary.TimeTyping*float64(time.Second)).Round(time.Second)),
+			fmt.Sprintf("WPM: %.1f mean • %.1f median • %.1f best", summary.WPM.Mean, summary.WPM.Median, summary.WPM.Max),
+			fmt.Sprintf("Accuracy: %.1f%% mean", summary.Accuracy.Mean))
+	} else {
+		head = append(head, historyDimStyle.Render("No matching sessions."))
+	}
+	if d.warning != "" {
+		head = append(head, "", errorStyle.Render(d.warning))
+	}
+	sections := []string{menuStyle.Render(strings.Join(head, "\n")), ""}
+
+	if len(runs) > 0 {
+		sections = append(sections,
+			historyStyle.Render(renderProgress(runs)), "",
+			historyStyle.Render(renderHeatmap(runs, m.cfg.Calendar, d.now, heatmapWeeks)), "")
+	}
+	if pbs := renderBests(d.bests, strings.Fields(strings.ToLower(d.query))); pbs != "" {
+		sections = append(sections, historyStyle.Render(pbs), "")
+	}
+	if len(records) > 0 {
+		sections = append(sections, historyStyle.Render(renderRecords(records)), "")
+	}
+
+	keys := m.cfg.Keys
+	hint := fmt.Sprintf("/ to filter • ↑/↓ to scroll • %s to go back", keys.Quit.Label())
+	if d.editing {
+		hint = "type to filter by mode, length, language, tier or date • Enter to finish"
+	}
+	sections = append(sections, hintStyle.Render(hint))
+	return m.applyScroll(lipgloss.JoinVertical(lipgloss.Center, sections...))
+}
+
+// renderProgress shows WPM and accuracy over the most recent tests, each
+// scaled to its own range so small changes stay visible.
+func renderProgress(runs []history.Record) string {
+	const label = "Accuracy "
+	recent := runs[max(len(runs)-(textWidth-len(label)-4), 0):]
+	wpm := make([]float64, len(recent))
+	acc := make([]float64, len(recent))
+	for i, r := range recent {
+		wpm[i], acc[i] = r.WPM, r.Accuracy
+	}
+
+	rows := []string{
+		hintStyle.Render(fmt.Sprintf("Last %d tests", len(recent))),
+		hintStyle.Render(fmt.Sprintf("%-*s", len(label), "WPM")) + chartNetStyle.Render(sparkline(wpm, slices.Min(wpm))),
+		hintStyle.Render(label) + correctStyle.Render(sparkline(acc, slices.Min(acc))),
+		"",
+	}
+	if t := stats.TrendOf(runs); t != nil {
+		rows = append(rows, fmt.Sprintf("Speed trend: %+.2f WPM/week since %s", t.WPMPerWeek, t.From.Format("Jan 02 2006")))
+	}
+	if len(runs) >= 2*dashboardRuns {
+		last := stats.Describe(accuracies(runs[len(runs)-dashboardRuns:])).Mean
+		before := stats.Describe(accuracies(runs[len(runs)-2*dashboardRuns : len(runs)-dashboardRuns])).Mean
+		rows = append(rows, fmt.Sprintf("Accuracy trend: %.1f%% over the last %d tests (%+.1f vs the %d before)",
+			last, dashboardRuns, last-before, dashboardRuns))
+	}
+	return strings.Join(rows, "\n")
+}
+
+func accuracies(records []history.Record) []float64 {
+	out := make([]float64, len(records))
+	for i, r := range records {
+		out[i] = r.Accuracy
+	}
+	return out
+}
+
+// renderHeatmap draws a calendar of the last weeks, one column per week
+// starting on Monday and one row per weekday, shaded by tests per
+// practice day.
+func renderHeatmap(records []history.Record, cal stats.Calendar, now time.Time, weeks int) string {
+	counts := make(map[string]int)
+	for _, r := range records {
+		counts[cal.Day(r.Date).Format(time.DateOnly)]++
+	}
+	today := cal.Day(now)
+	sinceMonday := (int(today.Weekday()) + 6) % 7
+	start := today.AddDate(0, 0, -sinceMonday-7*(weeks-1))
+
+	top, days := 0, 0
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if n := counts[day.Format(time.DateOnly)]; n > 0 {
+			top = max(top, n)
+			days++
+		}
+	}
+
+	// Month names sit above the week they start in, when there is room.
+	months := []rune(strings.Repeat(" ", 4+weeks))
+	free := 0
+	for col := range weeks {
+		day := start.AddDate(0, 0, 7*col)
+		if col > 0 && day.Month() == day.AddDate(0, 0, -7).Month() {
+			continue
+		}
+		name := day.Format("Jan")
+		if col >= free && col+len(name) <= weeks {
+			copy(months[4+col:], []rune(name))
+			free = col + len(name) + 1
+		}
+	}
+
+	rows := []string{
+		hintStyle.Render(fmt.Sprintf("Days practised: %d in the last %d weeks", days, weeks)),
+		hintStyle.Render(strings.TrimRight(string(months), " ")),
+	}
+	for weekday := range 7 {
+		label := "    "
+		switch weekday {
+		case 0, 2, 4:
+			label = start.AddDate(0, 0, weekday).Format("Mon") + " "
+		}
+		var b strings.Builder
+		b.WriteString(hintStyle.Render(label))
+		for col := range weeks {
+			day := start.AddDate(0, 0, 7*col+weekday)
+			n := counts[day.Format(time.DateOnly)]
+			switch {
+			case day.After(today):
+				b.WriteByte(' ')
+			case n == 0:
+				b.WriteString(remainStyle.Render(string(heatLevels[0])))
+			default:
+				level := len(heatLevels) - 1
+				if top > 1 {
+					level = 1 + (n-1)*(len(heatLevels)-2)/(top-1)
+				}
+				b.WriteString(chartNetStyle.Render(string(heatLevels[level])))
+			}
+		}
+		rows = append(rows, b.String())
+	}
+	rows = append(rows, "    "+hintStyle.Render("less ")+chartNetStyle.Render(string(heatLevels[1:]))+hintStyle.Render(" more"))
+	return strings.Join(rows, "\n")
+}
+
+// renderBests lists the personal bests whose record matches the terms.
+func renderBests(bests map[history.Key]history.Record, terms []string) string {
+	var rows []string
+	for _, k := range slices.SortedFunc(maps.Keys(bests), history.CompareKeys) {
+		r := bests[k]
+		if !matchesTerms(r, terms) {
+			continue
+		}
+		opts := k.Modifiers
+		if opts == "" {
+			opts = "-"
+		}
+		rows = append(rows, historyDimStyle.Render(fmt.Sprintf("%-10s %-9s %-9s %-12s %6.1f %6.1f%% %s",
+			k.Mode, k.Label(), k.Language, opts, r.WPM, r.Accuracy, r.Date.Format("2006-01-02"))))
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+	head := []string{
+		hintStyle.Render("Personal Bests"),
+		historyDimStyle.Render(fmt.Sprintf("%-10s %-9s %-9s %-12s %6s %7s %s", "Mode", "Test", "Language", "Options", "WPM", "Acc", "Date")),
+	}
+	return strings.Join(append(head, rows...), "\n")
+}
+
+// renderRecords lists records newest first.
+func renderRecords(records []history.Record) string {
+	rows := []string{
+		hintStyle.Render(fmt.Sprintf("All Sessions (%d)", len(records))),
+		historyDimStyle.Render(fmt.Sprintf("%-16s %-10s %-9s %6s %6s %7s %s", "Date", "Mode", "Test", "WPM", "Raw", "Acc", "Tier")),
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+		rows = append(rows, historyDimStyle.Render(fmt.Sprintf("%-16s %-10s %-9s %6.1f %6.1f %6.1f%% %s",
+			r.Date.Format("2006-01-02 15:04"), r.Mode, r.Label(), r.WPM, r.RawWPM, r.Accuracy, r.Tier)))
+	}
+	return strings.Join(rows, "\n")
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"terminal-wpm/internal/history"
+	"terminal-wpm/internal/stats"
+)
+
+func TestMatchesTerms(t *testing.T) {
+	r := history.Record{
+		Date: time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC), Mode: "words", TestType: "time", TimeLimit: 60,
+		Language: "english", Modifiers: []string{"numbers", "punctuation"}, Tier: "Fast",
+	}
+	for _, tc := range []struct {
+		terms []string
+		want  bool
+	}{
+		{nil, true},
+		{[]string{"60s"}, true},
+		{[]string{"words", "english"}, true},
+		{[]string{"numbers+punctuation"}, true},
+		{[]string{"2026-03"}, true},
+		{[]string{"fast"}, true},
+		{[]string{"words", "code"}, false},
+		{[]string{"quote"}, false},
+	} {
+		if got := matchesTerms(r, tc.terms); got != tc.want {
+			t.Errorf("%q: got %v, want %v", tc.terms, got, tc.want)
+		}
+	}
+}
+
+func TestRenderHeatmap(t *testing.T) {
+	at := func(d, hour int) history.Record {
+		return history.Record{Date: time.Date(2026, 3, d, hour, 0, 0, 0, time.UTC)}
+	}
+	feb := func(d int) history.Record {
+		return history.Record{Date: time.Date(2026, 2, d, 12, 0, 0, 0, time.UTC)}
+	}
+	for _, tc := range []struct {
+		name     string
+		records  []history.Record
+		rollover int
+		now      time.Time
+		days     int
+		grid     []string // Monday to Sunday, one column per week
+	}{
+		{
+			// Weeks start on Monday; the Sunday before the first week is
+			// left out and days after today stay blank. One test a day is
+			// the busiest day, so it is shaded in full.
+			name:    "weeks",
+			records: []history.Record{feb(22), feb(23), at(1, 12), at(4, 9)},
+			now:     time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC),
+			days:    3,
+			grid:    []string{"█·", "··", "·█", "· ", "· ", "· ", "█ "},
+		},
+		{
+			name:    "shading",
+			records: []history.Record{at(2, 9), at(3, 9), at(3, 10), at(4, 9), at(4, 10), at(4, 11)},
+			now:     time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC),
+			days:    3,
+			grid:    []string{"·░", "·▒", "·█", "· ", "· ", "· ", "· "},
+		},
+		{
+			// Before the rollover the night still belongs to Wednesday.
+			name:     "rollover",
+			records:  []history.Record{at(4, 10), at(5, 1)},
+			rollover: 4,
+			now:      time.Date(2026, 3, 5, 2, 0, 0, 0, time.UTC),
+			days:     1,
+			grid:     []string{"··", "··", "·█", "· ", "· ", "· ", "· "},
+		},
+	} {
+		cal := stats.Calendar{Location: time.UTC, Rollover: tc.rollover}
+		rows := strings.Split(renderHeatmap(tc.records, cal, tc.now, 2), "\n")
+		if want := fmt.Sprintf("Days practised: %d in the last 2 weeks", tc.days); rows[0] != want {
+			t.Errorf("%s: got %q, want %q", tc.name, rows[0], want)
+		}
+		var grid []string
+		for _, row := range rows[2:9] {
+			grid = append(grid, string([]rune(row)[4:]))
+		}
+		if !slices.Equal(grid, tc.grid) {
+			t.Errorf("%s: got grid %q, want %q", tc.name, grid, tc.grid)
+		}
+	}
+}
@@ -2,16 +2,26 @@ package app
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 
+	"terminal-wpm/internal/config"
+	"terminal-wpm/internal/content"
+	"terminal-wpm/internal/engine"
 	"terminal-wpm/internal/history"
 )
 
 const (
 	panelWidth = 72
+
+	// textWidth is the room inside textStyle's horizontal padding.
+	textWidth = panelWidth - 2
+
+	// timedLines is how many lines of streaming text a timed test shows.
+	timedLines = 3
 )
 
 var (
@@ -23,6 +33,8 @@ var (
 	currentStyle = lipgloss.NewStyle().Underline(true).Foreground(lipgloss.Color("16")).Background(lipgloss.Color("229"))
 	endCursor    = lipgloss.NewStyle().Foreground(lipgloss.Color("16")).Background(lipgloss.Color("229")).Render(" ")
 	remainStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	missedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Faint(true).Underline(true)
+	extraStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("88"))
 
 	selectedStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("229")).Background(lipgloss.Color("63")).Padding(0, 2)
 	unselectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Padding(0, 2)
@@ -54,17 +66,60 @@ var (
 
 	historyDimStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
 
+	chartNetStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	chartRawStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+
 	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+	pbStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Bold(true)
 )
 
+// applyPalette recolors the text styles from a config theme. Empty colors
+// leave the built-in style untouched.
+func applyPalette(p config.Colors) {
+	if p.Accent != "" {
+		titleStyle = titleStyle.Foreground(lipgloss.Color(p.Accent))
+		chartNetStyle = chartNetStyle.Foreground(lipgloss.Color(p.Accent))
+		pbStyle = pbStyle.Foreground(lipgloss.Color(p.Accent))
+	}
+	if p.Dim != "" {
+		hintStyle = hintStyle.Foreground(lipgloss.Color(p.Dim))
+		historyDimStyle = historyDimStyle.Foreground(lipgloss.Color(p.Dim))
+		chartRawStyle = chartRawStyle.Foreground(lipgloss.Color(p.Dim))
+	}
+	if p.Correct != "" {
+		correctStyle = correctStyle.Foreground(lipgloss.Color(p.Correct))
+	}
+	if p.Wrong != "" {
+		wrongStyle = wrongStyle.Foreground(lipgloss.Color(p.Wrong))
+		missedStyle = missedStyle.Foreground(lipgloss.Color(p.Wrong))
+		extraStyle = extraStyle.Foreground(lipgloss.Color(p.Wrong)).Faint(true)
+		errorStyle = errorStyle.Foreground(lipgloss.Color(p.Wrong))
+	}
+	if p.Cursor != "" {
+		currentStyle = currentStyle.Background(lipgloss.Color(p.Cursor))
+		endCursor = lipgloss.NewStyle().Foreground(lipgloss.Color("16")).Background(lipgloss.Color(p.Cursor)).Render(" ")
+	}
+	if p.Remaining != "" {
+		remainStyle = remainStyle.Foreground(lipgloss.Color(p.Remaining))
+	}
+}
+
 func (m model) viewMenu() string {
 	var rows []string
 	rows = append(rows, titleStyle.Render("Terminal WPM"))
 	rows = append(rows, "")
-	rows = append(rows, "Choose word count:")
+	if lines := m.streakLines(); lines != nil {
+		rows = append(rows, lines...)
+		rows = append(rows, "")
+	}
+	if m.cfg.Mode == "quote" {
+		rows = append(rows, "Choose quote length:")
+	} else {
+		rows = append(rows, "Choose word count:")
+	}
 	rows = append(rows, "")
 
-	for i, opt := range wordOptions {
+	for i, opt := range m.options {
 		if i == m.menuIdx {
 			rows = append(rows, selectedStyle.Render("▸ "+opt.label))
 		} else {
@@ -73,12 +128,39 @@ func (m model) viewMenu() string {
 	}
 
 	rows = append(rows, "")
-	rows = append(rows, hintStyle.Render("↑/↓ to move • Enter to start • Ctrl+C to quit"))
+	keys := m.cfg.Keys
+	hint := fmt.Sprintf("↑/↓ to move • %s to start • %s for stats • %s to quit", keys.Start.Label(), keys.Stats.Label(), keys.Quit.Label())
+	if m.modifiable() {
+		rows = append(rows, m.modifierRow(), "")
+		hint = fmt.Sprintf("1-%d to toggle • ", len(content.Modifiers())) + hint
+	}
+	if m.cfg.SwitchProfile != nil && len(m.cfg.Profiles) > 1 {
+		rows = append(rows, "Profile: "+selectedStyle.Render(m.profileName()), "")
+		hint += fmt.Sprintf(" • %s to switch profile", keys.Profile.Label())
+	} else if m.cfg.Profile != "" {
+		rows = append(rows, "Profile: "+m.profileName(), "")
+	}
+	rows = append(rows, hintStyle.Render(hint))
 
 	box := menuStyle.Render(strings.Join(rows, "\n"))
 	return m.applyScroll(box)
 }
 
+// modifierRow lists the text modifiers with their toggle keys, the active
+// ones highlighted.
+func (m model) modifierRow() string {
+	var cells []string
+	for i, mod := range content.Modifiers() {
+		label := fmt.Sprintf("%d %s", i+1, mod)
+		if slices.Contains(m.cfg.Modifiers, mod) {
+			cells = append(cells, selectedStyle.Render(label))
+		} else {
+			cells = append(cells, unselectedStyle.Render(label))
+		}
+	}
+	return strings.Join(cells, "")
+}
+
 func (m model) viewLive() string {
 	metrics := m.session.Snapshot(m.now, false, false)
 	elapsed := m.session.Elapsed(m.now)
@@ -91,21 +173,47 @@ func (m model) viewLive() string {
 		fmt.Sprintf("Elapsed: %s", formatDuration(elapsed)),
 		fmt.Sprintf("Errors: %d", metrics.Errors),
 	}
-	if m.cfg.TimeLimit > 0 {
-		remaining := m.cfg.TimeLimit - elapsed
+	if limit := m.session.TimeLimit(); limit > 0 {
+		remaining := limit - elapsed
 		if remaining < 0 {
 			remaining = 0
 		}
 		statsRows = append(statsRows, fmt.Sprintf("Time Left: %s", formatDuration(remaining)))
 	}
 
+	length := fmt.Sprintf("Words: %d", len(m.session.Words()))
+	if len(m.cfg.Pages) > 1 {
+		length += fmt.Sprintf("  •  Page %d/%d", m.page+1, len(m.cfg.Pages))
+	}
+	maxLines := 0
+	if m.session.Kind() == engine.KindTime {
+		length = fmt.Sprintf("Time: %s", formatDuration(m.cfg.TimeLimit))
+		maxLines = timedLines
+	}
+	mode := m.cfg.Mode
+	if m.practice {
+		mode = "practice"
+	} else if mods := m.modifiers(); len(mods) > 0 {
+		mode += " + " + strings.Join(mods, " + ")
+	}
 	header := titleStyle.Render("Terminal WPM") + "\n" +
-		hintStyle.Render(fmt.Sprintf("Mode: %s  •  Words: %d  •  Start typing to begin timer", m.cfg.Mode, m.cfg.WordCount))
+		hintStyle.Render(fmt.Sprintf("Mode: %s  •  %s  •  Start typing to begin timer", mode, length))
 
-	typedText := renderTarget(m.target, m.session.Input())
+	typedText := renderTarget(m.session.Words(), m.session.CurrentWord(), m.session.IsCompleted(), textWidth, maxLines)
 	main := textStyle.Width(panelWidth).Render(typedText)
 	stats := statsStyle.Width(panelWidth).Render(strings.Join(statsRows, "\n"))
-	footer := hintStyle.Render("Backspace to correct • Ctrl+C to stop")
+	hints := []string{"Backspace to correct"}
+	if m.session.Multiline() {
+		hints = append(hints, "Enter ↵ • Tab →")
+	}
+	if restart := m.restartKeys(); len(restart) > 0 {
+		hints = append(hints, restart.Label()+" then Enter to restart")
+	}
+	hints = append(hints, m.cfg.Keys.Stop.Label()+" to stop")
+	footer := hintStyle.Render(strings.Join(hints, " • "))
+	if m.armed {
+		footer = titleStyle.Render("Press Enter to restart")
+	}
 
 	content := lipgloss.JoinVertical(lipgloss.Left, header, "", main, "", stats, "", footer)
 	return m.applyScroll(content)
@@ -122,61 +230,359 @@ func (m model) viewSummary() string {
 		resultLabel = "Stopped by user"
 	}
 
-	body := strings.Join([]string{
+	rows := []string{
 		titleStyle.Render("Typing Test Results"),
 		"",
+		fmt.Sprintf("Test: %s", m.summaryTestLabel()),
+	}
+	switch {
+	case m.quote != nil:
+		rows = append(rows, historyDimStyle.Render("— "+m.quote.Attribution()))
+	case m.snippet != nil:
+		rows = append(rows, historyDimStyle.Render("— "+m.snippet.Attribution()))
+	}
+	rows = append(rows,
 		fmt.Sprintf("WPM: %.1f", metrics.WPM),
 		fmt.Sprintf("Raw WPM: %.1f", metrics.RawWPM),
-		fmt.Sprintf("Accuracy: %.1f%%", metrics.Accuracy),
+		fmt.Sprintf("Accuracy: %.1f%% (raw %.1f%%)", metrics.Accuracy, metrics.RawAccuracy),
 		fmt.Sprintf("Correct Words: %d / %d", metrics.CorrectWords, metrics.TotalWords),
-		fmt.Sprintf("Total errors: %d", metrics.Errors),
+		fmt.Sprintf("Errors: %d uncorrected, %d corrected", metrics.UncorrectedErrors, metrics.CorrectedErrors),
+		fmt.Sprintf("  %d wrong • %d extra • %d missed • %d swapped",
+			metrics.Substitutions, metrics.Insertions, metrics.Omissions, metrics.Transpositions),
+		fmt.Sprintf("Keystrokes: %d (%d backspaces)", metrics.Keystrokes, metrics.Backspaces),
+		fmt.Sprintf("Consistency: %.0f%%", metrics.Consistency),
+		fmt.Sprintf("Burst: %.1f WPM", metrics.BurstWPM),
 		fmt.Sprintf("Time taken: %s", formatDuration(metrics.TimeTaken)),
-		fmt.Sprintf("Tier: %s", performanceTier(metrics.WPM)),
+		fmt.Sprintf("Tier: %s", history.Tier(metrics.WPM)),
+		m.pbLine(),
+	)
+	rows = append(rows, m.unlockLines()...)
+	rows = append(rows,
 		fmt.Sprintf("Result: %s", resultLabel),
 		"",
-		hintStyle.Render("Press Enter, q, or Esc to exit"),
-	}, "\n")
+	)
+	if lines := m.streakLines(); lines != nil {
+		rows = append(append(rows, lines...), "")
+	}
+	body := strings.Join(append(rows, m.doneHint()), "\n")
 
 	boxed := finalStyle.Render(body)
+	sections := []string{boxed, ""}
+
+	if len(m.missed) > 0 {
+		sections = append(sections, renderMissed(m.missed, m.cfg.Keys.Practice.Label()), "")
+	}
+
+	// Speed over time, sized to the terminal.
+	chartWidth := panelWidth
+	if m.width > 0 {
+		chartWidth = min(m.width-4, 2*panelWidth)
+	}
+	if chart := renderChart(metrics.Samples, chartWidth); chart != "" {
+		sections = append(sections, chart, "")
+	}
 
 	// Append recent history below the result box.
 	historyBox := renderHistory(m.history)
 
 	scrollHint := hintStyle.Render("↑/↓ to scroll")
-	combined := lipgloss.JoinVertical(lipgloss.Center, boxed, "", historyBox, "", scrollHint)
+	sections = append(sections, historyBox, "", scrollHint)
+	combined := lipgloss.JoinVertical(lipgloss.Center, sections...)
 	return m.applyScroll(combined)
 }
 
-func renderTarget(target string, input []rune) string {
-	targetRunes := []rune(target)
-	var builder strings.Builder
-	cursor := len(input)
-
-	for i, r := range targetRunes {
-		if i < len(input) {
-			if input[i] == r {
-				builder.WriteString(correctStyle.Render(string(r)))
-			} else {
-				builder.WriteString(wrongStyle.Render(string(r)))
+// renderTarget colors each word against its input, wrapped to width.
+// Letters typed past the end of a word are shown as overflow and letters
+// skipped with space are marked as gaps. Line breaks in the target are
+// kept, with markers for the line ends and indentation to type. When
+// maxLines > 0 only that many lines are shown, scrolled so the cursor's
+// line stays second from the top.
+func renderTarget(words []engine.WordState, current int, done bool, width, maxLines int) string {
+	lines := wrapWords(words, width)
+
+	first, last := 0, len(lines)
+	if maxLines > 0 && len(lines) > maxLines {
+		cursorLine := len(lines) - 1
+		for i, ln := range lines {
+			if current < ln[1] {
+				cursorLine = i
+				break
 			}
-		} else if i == cursor {
-			if r == ' ' {
-				builder.WriteString(currentStyle.Render("·"))
-			} else {
-				builder.WriteString(currentStyle.Render(string(r)))
+		}
+		first = max(cursorLine-1, 0)
+		last = min(first+maxLines, len(lines))
+	}
+
+	rendered := make([]string, 0, last-first)
+	for _, ln := range lines[first:last] {
+		var b strings.Builder
+		for i := ln[0]; i < ln[1]; i++ {
+			active := i == current && !done
+			if i == ln[0] {
+				b.WriteString(renderIndent(words[i], active))
+			}
+			b.WriteString(renderWord(words[i], active))
+			if i < len(words)-1 || active {
+				b.WriteString(renderSep(words[i], active))
 			}
-		} else {
-			builder.WriteString(remainStyle.Render(string(r)))
 		}
+		rendered = append(rendered, b.String())
+	}
+	out := strings.Join(rendered, "\n")
+	if done {
+		out += endCursor
 	}
+	return out
+}
 
-	if cursor >= len(targetRunes) {
-		builder.WriteString(endCursor)
+// displayWidth is how many cells a word takes, including overflow.
+func displayWidth(w engine.WordState) int {
+	return max(len(w.Target), len(w.Typed))
+}
+
+// wrapWords groups words into [start, end) lines no wider than width,
+// counting one cell for the space after each word. A line break in the
+// target always starts a new line.
+func wrapWords(words []engine.WordState, width int) [][2]int {
+	var lines [][2]int
+	start, used := 0, 0
+	for i, w := range words {
+		need := displayWidth(w)
+		switch {
+		case i > start && slices.Contains(words[i-1].Sep, '\n'):
+			lines = append(lines, [2]int{start, i})
+			start, used = i, indentWidth(w.Indent)
+		case i > start && used+need+1 > width:
+			lines = append(lines, [2]int{start, i})
+			start, used = i, 0
+		case i == start:
+			used += indentWidth(w.Indent)
+		}
+		used += need + 1
+	}
+	if start < len(words) {
+		lines = append(lines, [2]int{start, len(words)})
 	}
+	return lines
+}
 
+func renderWord(w engine.WordState, active bool) string {
+	var builder strings.Builder
+	for i, r := range w.Target {
+		switch {
+		case i < len(w.Typed) && w.Typed[i] == r:
+			builder.WriteString(correctStyle.Render(string(r)))
+		case i < len(w.Typed):
+			builder.WriteString(wrongStyle.Render(string(r)))
+		case active && i == len(w.Typed):
+			builder.WriteString(currentStyle.Render(string(r)))
+		case w.Committed:
+			builder.WriteString(missedStyle.Render(string(r)))
+		default:
+			builder.WriteString(remainStyle.Render(string(r)))
+		}
+	}
+	if len(w.Typed) > len(w.Target) {
+		builder.WriteString(extraStyle.Render(string(w.Typed[len(w.Target):])))
+	}
 	return builder.String()
 }
 
+// renderSep draws the space after a word, or ↵ where Enter is expected,
+// highlighted when the cursor is on it and red when the wrong key ended
+// the word.
+func renderSep(w engine.WordState, active bool) string {
+	want, mark := ' ', "·"
+	if slices.Contains(w.Sep, '\n') {
+		want, mark = '\n', "↵"
+	}
+	switch {
+	case active && len(w.Typed) >= len(w.Target):
+		return currentStyle.Render(mark)
+	case w.Ended != 0 && w.Ended != want && len(w.Sep) > 0:
+		return wrongStyle.Render(mark)
+	case want == '\n':
+		return remainStyle.Render(mark)
+	}
+	return remainStyle.Render(" ")
+}
+
+// tabWidth is how many cells a tab of indentation takes on screen.
+const tabWidth = 4
+
+// indentWidth is how many cells indentation takes on screen.
+func indentWidth(indent []rune) int {
+	n := 0
+	for _, r := range indent {
+		if r == '\t' {
+			n += tabWidth
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// renderIndent draws the indentation before a word that starts a line:
+// → for a tab and · for a space, colored like letters as they are typed.
+func renderIndent(w engine.WordState, active bool) string {
+	var b strings.Builder
+	for i, r := range w.Indent {
+		mark := "·"
+		if r == '\t' {
+			mark = "→" + strings.Repeat(" ", tabWidth-1)
+		}
+		switch {
+		case i < len(w.Lead) && w.Lead[i] == r:
+			b.WriteString(historyDimStyle.Render(mark))
+		case i < len(w.Lead):
+			b.WriteString(wrongStyle.Render(mark))
+		case active && i == len(w.Lead) && len(w.Typed) == 0:
+			b.WriteString(currentStyle.Render(mark))
+		default:
+			b.WriteString(remainStyle.Render(mark))
+		}
+	}
+	return b.String()
+}
+
+// testLabel describes the test length, e.g. "60 words" or "30 seconds".
+func testLabel(metrics engine.Metrics, wordCount int) string {
+	if metrics.Kind == engine.KindTime {
+		return fmt.Sprintf("%g seconds", metrics.TimeLimit.Seconds())
+	}
+	return fmt.Sprintf("%d words", wordCount)
+}
+
+// pbLine compares the result with the personal best for its configuration.
+func (m model) pbLine() string {
+	switch {
+	case m.practice:
+		return "PB: not tracked for practice"
+	case m.ownText():
+		return "PB: not tracked for your own text or code"
+	case m.newPB && m.pb == nil:
+		return pbStyle.Render("★ New personal best!")
+	case m.newPB:
+		return pbStyle.Render(fmt.Sprintf("★ New personal best! +%.1f over %.1f WPM", m.final.WPM-m.pb.WPM, m.pb.WPM))
+	case m.pb != nil && !m.final.Cancelled:
+		return fmt.Sprintf("PB: %.1f WPM (%+.1f)", m.pb.WPM, m.final.WPM-m.pb.WPM)
+	case m.pb != nil:
+		return fmt.Sprintf("PB: %.1f WPM", m.pb.WPM)
+	default:
+		return "PB: none yet"
+	}
+}
+
+// maxUnlocksShown caps the achievements announced on the summary screen.
+const maxUnlocksShown = 3
+
+// unlockLines announces the achievements the last test unlocked.
+func (m model) unlockLines() []string {
+	var rows []string
+	for i, st := range m.unlocked {
+		if i == maxUnlocksShown {
+			rows = append(rows, hintStyle.Render(fmt.Sprintf("  …and %d more (typr achievements)", len(m.unlocked)-i)))
+			break
+		}
+		rows = append(rows, pbStyle.Render("◆ Achievement unlocked: "+st.Name)+hintStyle.Render(" — "+st.Description))
+	}
+	return rows
+}
+
+// streakLines shows today's progress toward the daily goal and the
+// streak, or nothing when results are not saved.
+func (m model) streakLines() []string {
+	if m.cfg.History == nil {
+		return nil
+	}
+	s, today := m.streak, m.streak.Today
+	var parts []string
+	if s.Goal.Tests > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d tests", today.Tests, s.Goal.Tests))
+	}
+	if s.Goal.Minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f/%g min", today.Minutes, s.Goal.Minutes))
+	}
+	if s.Goal.WPM > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f/%g WPM", today.WPM, s.Goal.WPM))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%d tests", today.Tests), fmt.Sprintf("%.1f min", today.Minutes))
+	}
+	goal := "Today: " + strings.Join(parts, " • ")
+	if s.GoalMet {
+		goal = pbStyle.Render(goal + " ✓")
+	}
+	return []string{goal, fmt.Sprintf("Streak: %s (best %s)", plural(s.Current, "day"), plural(s.Longest, "day"))}
+}
+
+// plural formats a count with its unit, e.g. "1 day" or "3 days".
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// doneHint lists the keys for leaving the results screen.
+func (m model) doneHint() string {
+	keys := m.cfg.Keys
+	if m.armed {
+		return titleStyle.Render("Press Enter to restart")
+	}
+	next := "a new test"
+	if len(m.cfg.Pages) > 1 {
+		next = "the next page"
+	}
+	return hintStyle.Render(fmt.Sprintf("%s for %s • %s then Enter to restart • %s to quit",
+		keys.Next.Label(), next, keys.Restart.Label(), keys.Quit.Label()))
+}
+
+// summaryTestLabel names the finished test, e.g. "words • 30 words".
+func (m model) summaryTestLabel() string {
+	if m.practice {
+		return fmt.Sprintf("practice • %d words", m.final.TotalWords)
+	}
+	if len(m.cfg.Pages) > 0 {
+		return fmt.Sprintf("%s • page %d/%d • %d words", m.cfg.Mode, m.page+1, len(m.cfg.Pages), m.final.TotalWords)
+	}
+	if m.quote != nil && m.final.Kind != engine.KindTime {
+		return fmt.Sprintf("%s • %s quote", m.cfg.Mode, m.quote.Length())
+	}
+	label := fmt.Sprintf("%s • %s", m.cfg.Mode, testLabel(m.final, m.cfg.WordCount))
+	if mods := m.modifiers(); len(mods) > 0 {
+		label += " • " + strings.Join(mods, ", ")
+	}
+	return label
+}
+
+// maxMissedShown caps the missed-words list on the summary screen.
+const maxMissedShown = 10
+
+// renderMissed lists mistyped words, expected next to typed, with a hint
+// for the practice key.
+func renderMissed(missed []engine.MissedWord, practiceKey string) string {
+	width := 0
+	for _, w := range missed[:min(len(missed), maxMissedShown)] {
+		width = max(width, len([]rune(w.Expected)))
+	}
+
+	rows := []string{hintStyle.Render("Missed Words")}
+	for i, w := range missed {
+		if i == maxMissedShown {
+			rows = append(rows, historyDimStyle.Render(fmt.Sprintf("…and %d more", len(missed)-i)))
+			break
+		}
+		pad := strings.Repeat(" ", width-len([]rune(w.Expected)))
+		rows = append(rows, correctStyle.Render(w.Expected)+pad+historyDimStyle.Render(" → ")+wrongStyle.Render(w.Typed))
+	}
+	if practiceKey != "" {
+		rows = append(rows, "", hintStyle.Render(fmt.Sprintf("Press %s to practice these words", practiceKey)))
+	}
+	return historyStyle.Render(strings.Join(rows, "\n"))
+}
+
 func formatDuration(d time.Duration) string {
 	if d < 0 {
 		d = 0
@@ -187,19 +593,6 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%02d:%02d", minutes, seconds)
 }
 
-func performanceTier(wpm float64) string {
-	switch {
-	case wpm < 30:
-		return "Beginner"
-	case wpm < 50:
-		return "Average"
-	case wpm < 80:
-		return "Fast"
-	default:
-		return "Elite"
-	}
-}
-
 func renderHistory(records []history.Record) string {
 	if len(records) == 0 {
 		return historyStyle.Render(historyDimStyle.Render("No previous sessions yet."))
@@ -207,11 +600,11 @@ func renderHistory(records []history.Record) string {
 
 	var rows []string
 	rows = append(rows, hintStyle.Render("Recent Sessions"))
-	rows = append(rows, historyDimStyle.Render(fmt.Sprintf("%-12s %6s %6s %7s %s", "Date", "WPM", "Raw", "Acc", "Tier")))
+	rows = append(rows, historyDimStyle.Render(fmt.Sprintf("%-12s %-9s %6s %6s %7s %7s %s", "Date", "Test", "WPM", "Raw", "Acc", "RawAcc", "Tier")))
 
 	for _, r := range records {
 		dateStr := r.Date.Format("Jan 02 15:04")
-		line := fmt.Sprintf("%-12s %6.1f %6.1f %6.1f%% %s", dateStr, r.WPM, r.RawWPM, r.Accuracy, r.Tier)
+		line := fmt.Sprintf("%-12s %-9s %6.1f %6.1f %6.1f%% %6.1f%% %s", dateStr, r.Label(), r.WPM, r.RawWPM, r.Accuracy, r.RawAccuracy, r.Tier)
 		rows = append(rows, historyDimStyle.Render(line))
 	}
 
@@ -0,0 +1,537 @@
+// Package config loads user settings. Values are layered: built-in
+// defaults, then the config file, then the active profile's config file,
+// then TYPR_* environment variables. Command-line flags are applied last
+// by the caller.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"terminal-wpm/internal/content"
+)
+
+// Settings holds everything a user can persist between runs.
+type Settings struct {
+	Mode        string     `json:"mode"`
+	WordCounts  []int      `json:"word_counts"`
+	TimeOptions []Duration `json:"time_options"`
+	TimeLimit   Duration   `json:"time_limit"`
+	Modifiers   []string   `json:"modifiers,omitempty"` // text options, see content.Modifiers
+
+	SampleInterval   Duration `json:"sample_interval"`    // bucket width for the speed series
+	PracticeRepeat   int      `json:"practice_repeat"`    // times each missed word appears in a drill
+	HistoryRetention int      `json:"history_retention"`  // results kept on disk; 0 keeps all of them
+	SyncDir          string   `json:"sync_dir,omitempty"` // shared directory for merging histories across machines
+	Profile          string   `json:"-"`                  // profile the settings were loaded for; "" is the default
+	DailyGoal        Goal     `json:"daily_goal"`
+	TimeZone         string   `json:"time_zone,omitempty"` // IANA name for practice days; "" is the system zone
+	DayRollover      int      `json:"day_rollover"`        // hour a new practice day starts, 0-23
+	AutoIndent       bool     `json:"auto_indent"`         // skip indentation after a line break in code
+	Sound            bool     `json:"sound"`
+	Theme            string   `json:"theme"`
+	Colors           Colors   `json:"colors"`
+	Keys             Keys     `json:"keys"`
+}
+
+// Colors are lipgloss color strings (ANSI numbers or #hex). Empty fields
+// fall back to the selected theme.
+type Colors struct {
+	Accent    string `json:"accent,omitempty"`
+	Correct   string `json:"correct,omitempty"`
+	Wrong     string `json:"wrong,omitempty"`
+	Cursor    string `json:"cursor,omitempty"`
+	Remaining string `json:"remaining,omitempty"`
+	Dim       string `json:"dim,omitempty"`
+}
+
+// Goal is a daily practice target. Zero fields are not checked, so an
+// empty goal is met by any test.
+type Goal struct {
+	Minutes float64 `json:"minutes,omitempty"` // time spent typing
+	Tests   int     `json:"tests,omitempty"`
+	WPM     float64 `json:"wpm,omitempty"` // mean speed of the day's tests
+}
+
+// Keys maps actions to bubbletea key names such as "enter" or "ctrl+c".
+type Keys struct {
+	Up       Binding `json:"up"`
+	Down     Binding `json:"down"`
+	Start    Binding `json:"start"`
+	Stop     Binding `json:"stop"`
+	Quit     Binding `json:"quit"`
+	Practice Binding `json:"practice"`
+	Restart  Binding `json:"restart"` // followed by Enter, retypes the same text
+	Next     Binding `json:"next"`    // on the results screen, starts a new text
+	Profile  Binding `json:"profile"` // on the menu, switches to the next profile
+	Stats    Binding `json:"stats"`   // on the menu, opens the statistics screen
+}
+
+// Binding is the list of keys that trigger one action.
+type Binding []string
+
+// Has reports whether key triggers the binding.
+func (b Binding) Has(key string) bool {
+	return slices.Contains(b, key)
+}
+
+// Without returns the binding minus keys, e.g. those a test types.
+func (b Binding) Without(keys ...string) Binding {
+	return slices.DeleteFunc(slices.Clone(b), func(k string) bool { return slices.Contains(keys, k) })
+}
+
+// Label returns a display name for the first key, e.g. "Ctrl+C".
+func (b Binding) Label() string {
+	if len(b) == 0 {
+		return ""
+	}
+	switch k := b[0]; k {
+	case " ":
+		return "Space"
+	default:
+		parts := strings.Split(k, "+")
+		for i, p := range parts {
+			if len(p) > 1 {
+				parts[i] = strings.ToUpper(p[:1]) + p[1:]
+			} else {
+				parts[i] = strings.ToUpper(p)
+			}
+		}
+		return strings.Join(parts, "+")
+	}
+}
+
+// Duration is a time.Duration that reads "60s" or a bare number of seconds.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var secs float64
+	if err := json.Unmarshal(data, &secs); err == nil {
+		*d = Duration(secs * float64(time.Second))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return &durationError{raw: data, msg: `want a duration like "60s" or a number of seconds`}
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return &durationError{raw: data, msg: fmt.Sprintf("invalid duration %q", s)}
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// durationError keeps the offending JSON so the caller can find its line.
+type durationError struct {
+	raw []byte
+	msg string
+}
+
+func (e *durationError) Error() string { return e.msg }
+
+var themes = map[string]Colors{
+	"default": {
+		Accent:    "39",
+		Correct:   "42",
+		Wrong:     "196",
+		Cursor:    "229",
+		Remaining: "240",
+		Dim:       "244",
+	},
+	"solarized": {
+		Accent:    "#268bd2",
+		Correct:   "#859900",
+		Wrong:     "#dc322f",
+		Cursor:    "#b58900",
+		Remaining: "#586e75",
+		Dim:       "#93a1a1",
+	},
+	"mono": {
+		Accent:    "15",
+		Correct:   "252",
+		Wrong:     "9",
+		Cursor:    "15",
+		Remaining: "240",
+		Dim:       "244",
+	},
+}
+
+// Themes lists the built-in theme names.
+func Themes() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Default returns the built-in settings.
+func Default() Settings {
+	return Settings{
+		Mode:       "words",
+		WordCounts: []int{30, 60},
+		TimeOptions: []Duration{
+			Duration(15 * time.Second),
+			Duration(30 * time.Second),
+			Duration(60 * time.Second),
+			Duration(120 * time.Second),
+		},
+		SampleInterval: Duration(time.Second),
+		PracticeRepeat: 3,
+		DayRollover:    4,
+		AutoIndent:     true,
+		Sound:          true,
+		Theme:          "default",
+		Keys: Keys{
+			Up:    Binding{"up", "k"},
+			Down:  Binding{"down", "j"},
+			Start: Binding{"enter", " "},
+			Stop:  Binding{"ctrl+c"},
+			Quit:  Binding{"ctrl+c", "q", "esc"},
+
+			Practice: Binding{"p"},
+			Restart:  Binding{"tab", "ctrl+r"},
+			Next:     Binding{"enter", "n"},
+			Profile:  Binding{"tab"},
+			Stats:    Binding{"s"},
+		},
+	}
+}
+
+// Palette resolves the theme and any color overrides.
+func (s Settings) Palette() Colors {
+	p := themes[s.Theme]
+	o := s.Colors
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&p.Accent, o.Accent},
+		{&p.Correct, o.Correct},
+		{&p.Wrong, o.Wrong},
+		{&p.Cursor, o.Cursor},
+		{&p.Remaining, o.Remaining},
+		{&p.Dim, o.Dim},
+	} {
+		if f.src != "" {
+			*f.dst = f.src
+		}
+	}
+	return p
+}
+
+// Location returns the time zone practice days are counted in.
+func (s Settings) Location() *time.Location {
+	if s.TimeZone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return time.Local // validate rejects unknown zones
+	}
+	return loc
+}
+
+// TimeLimits returns TimeOptions as plain durations.
+func (s Settings) TimeLimits() []time.Duration {
+	limits := make([]time.Duration, 0, len(s.TimeOptions))
+	for _, d := range s.TimeOptions {
+		limits = append(limits, time.Duration(d))
+	}
+	return limits
+}
+
+// Dir returns the terminal-wpm directory inside the user's config dir.
+func Dir() (string, error) {
+	cfgDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cfgDir, "terminal-wpm"), nil
+}
+
+// Path returns the config file location. TYPR_CONFIG overrides it.
+func Path() (string, error) {
+	if p := os.Getenv("TYPR_CONFIG"); p != "" {
+		return p, nil
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// Load returns the defaults overlaid with the config file (if present)
+// and the environment.
+func Load() (Settings, error) {
+	return LoadProfile("")
+}
+
+// LoadProfile is like Load but also overlays the config file of the named
+// profile. An empty name or "default" is the default profile.
+func LoadProfile(name string) (Settings, error) {
+	s := Default()
+	name, err := profileName(name)
+	if err != nil {
+		return s, err
+	}
+	s.Profile = name
+
+	path, err := Path()
+	if err != nil {
+		return s, err
+	}
+	if err := overlayFile(path, &s); err != nil {
+		return s, err
+	}
+	if name != "" {
+		dir, err := ProfileDir(name)
+		if err != nil {
+			return s, err
+		}
+		if err := overlayFile(filepath.Join(dir, "config.json"), &s); err != nil {
+			return s, err
+		}
+	}
+
+	if err := applyEnv(&s, os.Getenv); err != nil {
+		return s, err
+	}
+	s.SyncDir = expandHome(s.SyncDir)
+	return s, nil
+}
+
+// overlayFile decodes the config file at path onto s, if it exists.
+func overlayFile(path string, s *Settings) error {
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil // no config file; keep what we have
+	case err != nil:
+		return err
+	default:
+		return decodeFile(path, data, s)
+	}
+}
+
+// expandHome replaces a leading "~/" with the home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
+
+// decodeFile overlays data onto s. Fields missing from the file keep
+// their current value.
+func decodeFile(path string, data []byte, s *Settings) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(s); err != nil {
+		return fileError(path, data, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		line, _ := position(data, dec.InputOffset())
+		return fmt.Errorf("%s:%d: unexpected content after the settings object", path, line)
+	}
+	if err := s.validate(); err != nil {
+		var ve *validationError
+		if errors.As(err, &ve) {
+			return fmt.Errorf("%s:%d: %s: %s", path, lineOfKey(data, ve.key), ve.key, ve.msg)
+		}
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// fileError rewrites a JSON decoding error to name the file and line.
+func fileError(path string, data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var durErr *durationError
+	switch {
+	case errors.As(err, &durErr):
+		line := 1
+		if idx := bytes.Index(data, durErr.raw); idx >= 0 {
+			line, _ = position(data, int64(idx))
+		}
+		return fmt.Errorf("%s:%d: %s", path, line, durErr.msg)
+	case errors.As(err, &syntaxErr):
+		line, col := position(data, syntaxErr.Offset)
+		return fmt.Errorf("%s:%d:%d: %s", path, line, col, syntaxErr.Error())
+	case errors.As(err, &typeErr):
+		line, _ := position(data, typeErr.Offset)
+		return fmt.Errorf("%s:%d: %s: cannot use %s here, want %s", path, line, typeErr.Field, typeErr.Value, typeErr.Type)
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("%s: file is empty", path)
+	}
+
+	msg := err.Error()
+	if field, ok := strings.CutPrefix(msg, "json: unknown field "); ok {
+		key, _ := strconv.Unquote(field)
+		return fmt.Errorf("%s:%d: unknown setting %s", path, lineOfKey(data, key), field)
+	}
+	return fmt.Errorf("%s: %s", path, strings.TrimPrefix(msg, "json: "))
+}
+
+// position converts a byte offset to a 1-based line and column.
+func position(data []byte, offset int64) (line, col int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	col = int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// lineOfKey finds the line where "key" first appears, or 1.
+func lineOfKey(data []byte, key string) int {
+	idx := bytes.Index(data, []byte(strconv.Quote(key)))
+	if idx < 0 {
+		return 1
+	}
+	line, _ := position(data, int64(idx))
+	return line
+}
+
+type validationError struct {
+	key string
+	msg string
+}
+
+func (e *validationError) Error() string {
+	return e.key + ": " + e.msg
+}
+
+func (s Settings) validate() error {
+	if !slices.Contains(content.Modes(), s.Mode) {
+		return &validationError{"mode", fmt.Sprintf("unknown mode %q (want %s)", s.Mode, strings.Join(content.Modes(), ", "))}
+	}
+	if len(s.WordCounts) == 0 && len(s.TimeOptions) == 0 {
+		return &validationError{"word_counts", "need at least one word count or time option"}
+	}
+	for _, n := range s.WordCounts {
+		if n <= 0 {
+			return &validationError{"word_counts", fmt.Sprintf("word count %d must be greater than zero", n)}
+		}
+	}
+	for _, d := range s.TimeOptions {
+		if d <= 0 {
+			return &validationError{"time_options", fmt.Sprintf("time option %s must be greater than zero", time.Duration(d))}
+		}
+	}
+	if s.TimeLimit < 0 {
+		return &validationError{"time_limit", "must not be negative"}
+	}
+	for _, mod := range s.Modifiers {
+		if !slices.Contains(content.Modifiers(), mod) {
+			return &validationError{"modifiers", fmt.Sprintf("unknown modifier %q (want %s)", mod, strings.Join(content.Modifiers(), ", "))}
+		}
+	}
+	if s.HistoryRetention < 0 {
+		return &validationError{"history_retention", "must not be negative (0 keeps everything)"}
+	}
+	if s.PracticeRepeat < 1 || s.PracticeRepeat > 50 {
+		return &validationError{"practice_repeat", "must be between 1 and 50"}
+	}
+	if g := s.DailyGoal; g.Minutes < 0 || g.Tests < 0 || g.WPM < 0 {
+		return &validationError{"daily_goal", "targets must not be negative"}
+	}
+	if _, err := time.LoadLocation(s.TimeZone); err != nil {
+		return &validationError{"time_zone", fmt.Sprintf("unknown time zone %q", s.TimeZone)}
+	}
+	if s.DayRollover < 0 || s.DayRollover > 23 {
+		return &validationError{"day_rollover", "must be an hour between 0 and 23"}
+	}
+	if s.SampleInterval < Duration(100*time.Millisecond) {
+		return &validationError{"sample_interval", "must be at least 100ms"}
+	}
+	if _, ok := themes[s.Theme]; !ok {
+		return &validationError{"theme", fmt.Sprintf("unknown theme %q (want %s)", s.Theme, strings.Join(Themes(), ", "))}
+	}
+	for name, b := range map[string]Binding{
+		"up": s.Keys.Up, "down": s.Keys.Down, "start": s.Keys.Start, "stop": s.Keys.Stop, "quit": s.Keys.Quit,
+		"practice": s.Keys.Practice, "restart": s.Keys.Restart, "next": s.Keys.Next,
+		"profile": s.Keys.Profile, "stats": s.Keys.Stats,
+	} {
+		if len(b) == 0 {
+			return &validationError{name, "key binding must list at least one key"}
+		}
+	}
+	return nil
+}
+
+// applyEnv overlays TYPR_* environment variables.
+func applyEnv(s *Settings, getenv func(string) string) error {
+	if v := getenv("TYPR_MODE"); v != "" {
+		s.Mode = v
+	}
+	if v := getenv("TYPR_WORD_COUNTS"); v != "" {
+		var counts []int
+		for _, field := range strings.Split(v, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				return fmt.Errorf("TYPR_WORD_COUNTS: %q is not a number", field)
+			}
+			counts = append(counts, n)
+		}
+		s.WordCounts = counts
+	}
+	if v := getenv("TYPR_MODIFIERS"); v != "" {
+		var mods []string
+		for _, field := range strings.Split(v, ",") {
+			if mod := strings.TrimSpace(field); mod != "" {
+				mods = append(mods, mod)
+			}
+		}
+		s.Modifiers = mods
+	}
+	if v := getenv("TYPR_TIME"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			s.TimeLimit = Duration(time.Duration(n) * time.Second)
+		} else if d, err := time.ParseDuration(v); err == nil {
+			s.TimeLimit = Duration(d)
+		} else {
+			return fmt.Errorf("TYPR_TIME: invalid duration %q", v)
+		}
+	}
+	if v := getenv("TYPR_SOUND"); v != "" {
+		on, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("TYPR_SOUND: %q is not a boolean", v)
+		}
+		s.Sound = on
+	}
+	if v := getenv("TYPR_THEME"); v != "" {
+		s.Theme = v
+	}
+	if v := getenv("TYPR_SYNC_DIR"); v != "" {
+		s.SyncDir = v
+	}
+	if err := s.validate(); err != nil {
+		return fmt.Errorf("environment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeFileOverlaysDefaults(t *testing.T) {
+	s := Default()
+	data := []byte(`{"mode": "code", "time_limit": "45s", "keys": {"quit": ["x"]}}`)
+	if err := decodeFile("config.json", data, &s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Mode != "code" {
+		t.Fatalf("expected mode code, got %q", s.Mode)
+	}
+	if time.Duration(s.TimeLimit) != 45*time.Second {
+		t.Fatalf("expected 45s, got %s", time.Duration(s.TimeLimit))
+	}
+	if !s.Keys.Quit.Has("x") || !s.Keys.Up.Has("up") {
+		t.Fatalf("expected quit overridden and up kept, got %+v", s.Keys)
+	}
+	if len(s.WordCounts) != 2 {
+		t.Fatalf("expected default word counts kept, got %v", s.WordCounts)
+	}
+}
+
+func TestDecodeFileErrorsNameLine(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"syntax", "{\n  \"mode\": \"code\",\n  \"sound\" true\n}", "config.json:3:"},
+		{"type", "{\n  \"word_counts\": \"thirty\"\n}", "config.json:2:"},
+		{"unknown", "{\n  \"mode\": \"code\",\n  \"colour\": {}\n}", "config.json:3: unknown setting"},
+		{"invalid", "{\n\n  \"theme\": \"neon\"\n}", "config.json:3: theme"},
+		{"duration", "{\n  \"time_limit\": \"soon\"\n}", "config.json:2: invalid duration"},
+		{"time zone", "{\n  \"time_zone\": \"Mars/Olympus\"\n}", "config.json:2: time_zone"},
+		{"rollover", "{\n  \"mode\": \"code\",\n  \"day_rollover\": 24\n}", "config.json:3: day_rollover"},
+		{"modifier", "{\n  \"modifiers\": [\"numbers\", \"emoji\"]\n}", "config.json:2: modifiers"},
+		{"duration list", "{\n  \"mode\": \"code\",\n  \"time_options\": [15, \"soon\"]\n}", "config.json:3: invalid duration"},
+	}
+	for _, tc := range cases {
+		s := Default()
+		err := decodeFile("config.json", []byte(tc.data), &s)
+		if err == nil {
+			t.Fatalf("%s: expected error", tc.name)
+		}
+		if !strings.HasPrefix(err.Error(), tc.want) {
+			t.Fatalf("%s: expected prefix %q, got %q", tc.name, tc.want, err.Error())
+		}
+	}
+}
+
+func TestApplyEnvOverridesFile(t *testing.T) {
+	s := Default()
+	s.Mode = "code"
+	env := map[string]string{
+		"TYPR_MODE":        "quote",
+		"TYPR_TIME":        "30",
+		"TYPR_SOUND":       "false",
+		"TYPR_WORD_COUNTS": "10, 20",
+		"TYPR_MODIFIERS":   "numbers, quotes",
+	}
+	if err := applyEnv(&s, func(k string) string { return env[k] }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Mode != "quote" || s.Sound || time.Duration(s.TimeLimit) != 30*time.Second {
+		t.Fatalf("env not applied: %+v", s)
+	}
+	if len(s.WordCounts) != 2 || s.WordCounts[1] != 20 {
+		t.Fatalf("expected word counts [10 20], got %v", s.WordCounts)
+	}
+	if len(s.Modifiers) != 2 || s.Modifiers[1] != "quotes" {
+		t.Fatalf("expected modifiers [numbers quotes], got %v", s.Modifiers)
+	}
+}
+
+func TestPaletteOverridesTheme(t *testing.T) {
+	s := Default()
+	s.Colors.Wrong = "#ff0000"
+	p := s.Palette()
+	if p.Wrong != "#ff0000" || p.Correct != themes["default"].Correct {
+		t.Fatalf("unexpected palette: %+v", p)
+	}
+}
+
+func TestLoadProfileOverlaysBaseConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("APPDATA", home)
+	t.Setenv("TYPR_CONFIG", "")
+	base, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ProfileDir("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(base, "config.json"), `{"mode": "code", "theme": "mono"}`)
+	writeFile(t, filepath.Join(dir, "config.json"), `{"theme": "solarized"}`)
+
+	s, err := LoadProfile("alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Profile != "alice" || s.Mode != "code" || s.Theme != "solarized" {
+		t.Fatalf("expected base mode and profile theme, got %+v", s)
+	}
+	if s, _ := LoadProfile(DefaultProfile); s.Profile != "" || s.Theme != "mono" {
+		t.Fatalf("expected the default profile to read only the base config, got %+v", s)
+	}
+
+	if err := RenameProfile("alice", "bob"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if names, _ := Profiles(); !slices.Equal(names, []string{DefaultProfile, "bob"}) {
+		t.Fatalf("expected [default bob], got %v", names)
+	}
+	if err := DeleteProfile(DefaultProfile); err == nil {
+		t.Fatalf("expected the default profile to be kept")
+	}
+	if _, err := LoadProfile("../etc"); err == nil {
+		t.Fatalf("expected an invalid profile name to be rejected")
+	}
+}
+
+func TestRenameAndDeleteProfileMoveSyncedShards(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("APPDATA", home)
+	t.Setenv("TYPR_CONFIG", "")
+	t.Setenv("TYPR_SYNC_DIR", "")
+	base, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sync := filepath.Join(home, "sync")
+	writeFile(t, filepath.Join(base, "config.json"), `{"sync_dir": "`+filepath.ToSlash(sync)+`"}`)
+	writeFile(t, filepath.Join(base, "profiles", "alice", "pbs.json"), `{}`)
+	writeFile(t, filepath.Join(sync, "profiles", "alice", "laptop-1.jsonl"), "{}\n")
+	writeFile(t, filepath.Join(sync, "profiles", "bob", "desk-2.jsonl"), "{}\n")
+
+	if err := RenameProfile("alice", "bob"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	for _, shard := range []string{"laptop-1.jsonl", "desk-2.jsonl"} {
+		if _, err := os.Stat(filepath.Join(sync, "profiles", "bob", shard)); err != nil {
+			t.Fatalf("expected %s under the new name: %v", shard, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(sync, "profiles", "alice")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the old sync directory to be gone, got %v", err)
+	}
+
+	if err := DeleteProfile("bob"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(sync, "profiles", "bob")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the synced shards to be deleted, got %v", err)
+	}
+}
+
+func TestRenameProfileRefusesClashingShards(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("APPDATA", home)
+	t.Setenv("TYPR_CONFIG", "")
+	t.Setenv("TYPR_SYNC_DIR", filepath.Join(home, "sync"))
+	base, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(base, "profiles", "alice", "pbs.json"), `{}`)
+	writeFile(t, filepath.Join(home, "sync", "profiles", "alice", "laptop-1.jsonl"), "{}\n")
+	writeFile(t, filepath.Join(home, "sync", "profiles", "bob", "laptop-1.jsonl"), "{}\n")
+
+	if err := RenameProfile("alice", "bob"); err == nil {
+		t.Fatal("expected clashing shards to stop the rename")
+	}
+	if _, err := os.Stat(filepath.Join(base, "profiles", "alice")); err != nil {
+		t.Fatalf("expected the profile to stay put: %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+// DefaultProfile names the profile that lives directly in Dir.
+const DefaultProfile = "default"
+
+var profilePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,31}$`)
+
+// profileName validates a profile name and maps the default profile to "".
+func profileName(name string) (string, error) {
+	if name == "" || name == DefaultProfile {
+		return "", nil
+	}
+	if !profilePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name %q: use up to 32 letters, digits, - or _", name)
+	}
+	return name, nil
+}
+
+// ProfileDir returns the directory holding a profile's history, personal
+// bests and config overrides. The default profile uses Dir itself.
+func ProfileDir(name string) (string, error) {
+	name, err := profileName(name)
+	if err != nil {
+		return "", err
+	}
+	dir, err := Dir()
+	if err != nil || name == "" {
+		return dir, err
+	}
+	return filepath.Join(dir, "profiles", name), nil
+}
+
+// Profiles lists the profile names, the default profile first.
+func Profiles() ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "profiles"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && profilePattern.MatchString(e.Name()) && e.Name() != DefaultProfile {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+// RenameProfile moves a profile's data to a new name, including its shards
+// in the sync directory when it syncs.
+func RenameProfile(from, to string) error {
+	src, dst, err := profilePair(from, to)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("profile %q already exists", to)
+	}
+	syncDir, err := profileSyncDir(from)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	if syncDir != "" {
+		if err := moveShards(syncDir, filepath.Join(filepath.Dir(syncDir), to)); err != nil {
+			return err
+		}
+	}
+	return os.Rename(src, dst)
+}
+
+// DeleteProfile removes a profile and all of its results, including the
+// shards every machine synced for it.
+func DeleteProfile(name string) error {
+	dir, err := existingProfile(name)
+	if err != nil {
+		return err
+	}
+	syncDir, err := profileSyncDir(name)
+	if err != nil {
+		return err
+	}
+	if syncDir != "" {
+		if err := os.RemoveAll(syncDir); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(dir)
+}
+
+// profileSyncDir returns the directory a profile's shards are synced to,
+// or "" when the profile does not sync. It mirrors history.Open.
+func profileSyncDir(name string) (string, error) {
+	s, err := LoadProfile(name)
+	if err != nil {
+		return "", fmt.Errorf("profile %q: %w", name, err)
+	}
+	if s.SyncDir == "" {
+		return "", nil
+	}
+	return filepath.Join(s.SyncDir, "profiles", s.Profile), nil
+}
+
+// moveShards moves the shards in src to dst. Other machines may already
+// have synced shards under the new name, so the two are merged; a shard
+// of the same name in both is an error, and nothing has been moved then.
+func moveShards(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil // nothing synced yet
+	} else if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if _, err := os.Stat(filepath.Join(dst, e.Name())); err == nil {
+			return fmt.Errorf("%s already exists; move the shards in %s by hand", filepath.Join(dst, e.Name()), src)
+		}
+	}
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := os.Rename(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			return err
+		}
+	}
+	return os.Remove(src)
+}
+
+func profilePair(from, to string) (string, string, error) {
+	src, err := existingProfile(from)
+	if err != nil {
+		return "", "", err
+	}
+	if n, err := profileName(to); err != nil {
+		return "", "", err
+	} else if n == "" {
+		return "", "", errors.New("cannot rename a profile to the default profile")
+	}
+	dst, err := ProfileDir(to)
+	return src, dst, err
+}
+
+// existingProfile returns the directory of a named profile that exists.
+// The default profile cannot be renamed or deleted.
+func existingProfile(name string) (string, error) {
+	n, err := profileName(name)
+	if err != nil {
+		return "", err
+	}
+	if n == "" {
+		return "", errors.New("the default profile cannot be renamed or deleted")
+	}
+	dir, err := ProfileDir(n)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("no profile named %q", name)
+	}
+	return dir, nil
+}
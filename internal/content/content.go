@@ -6,7 +6,7 @@ import (
 	"strings"
 )
 
-var quoteWords = []string{
+var commonWords = []string{
 	// common English words (matches monkeytype / typeracer pools)
 	"the", "be", "to", "of", "and", "a", "in", "that", "have", "I",
 	"it", "for", "not", "on", "with", "he", "as", "you", "do", "at",
@@ -30,7 +30,7 @@ var quoteWords = []string{
 	"love", "stand", "bring", "hard", "begin", "air", "kind", "mean", "leave", "story",
 }
 
-var quoteWordsExtra = []string{
+var commonWordsExtra = []string{
 	"able", "account", "across", "action", "actually", "address", "administration", "admit", "adult", "affect",
 	"against", "age", "agency", "agent", "ago", "agree", "agreement", "ahead", "allow", "almost",
 	"alone", "along", "already", "although", "always", "among", "amount", "analysis", "animal", "another",
@@ -162,34 +162,102 @@ var codeWordsExtra = []string{
 	"serializer", "deserializer", "marshaller", "demarshaller", "adapterpattern", "statepattern", "eventstore", "snapshotter", "commandbus", "querybus",
 }
 
-var quotePool = uniqueWords(append(append([]string{}, quoteWords...), quoteWordsExtra...))
+// Language names the language of the built-in word banks.
+const Language = "english"
+
+var wordsPool = uniqueWords(append(append([]string{}, commonWords...), commonWordsExtra...))
 var codePool = uniqueWords(append(append([]string{}, codeWords...), codeWordsExtra...))
 
+// RandomText returns wordCount random words from the pool for mode. Quote
+// mode has no pool; see RandomQuote.
 func RandomText(mode string, wordCount int) (string, error) {
+	return RandomTextWith(rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())), mode, wordCount)
+}
+
+// RandomTextWith is like RandomText but draws from rng, so a fixed seed
+// reproduces the same text.
+func RandomTextWith(rng *rand.Rand, mode string, wordCount int) (string, error) {
+	pool, err := wordPool(mode)
+	if err != nil {
+		return "", err
+	}
+	return WordsFrom(rng, pool, wordCount)
+}
+
+// WordsFrom returns wordCount words drawn at random from pool, such as a
+// custom word list.
+func WordsFrom(rng *rand.Rand, pool []string, wordCount int) (string, error) {
 	if wordCount <= 0 {
 		return "", errors.New("word count must be greater than zero")
 	}
+	if len(pool) == 0 {
+		return "", errors.New("no words to choose from")
+	}
 
 	words := make([]string, 0, wordCount)
+	for range wordCount {
+		words = append(words, pool[rng.IntN(len(pool))])
+	}
 
+	return strings.Join(words, " "), nil
+}
+
+// streamChunk is how many words WordStream returns per call.
+const streamChunk = 20
+
+// WordStream returns a generator for endless text in mode, used by timed
+// tests. Each call yields the next chunk of words, or in quote mode the
+// next quote.
+func WordStream(rng *rand.Rand, mode string) (func() string, error) {
+	if mode == "quote" {
+		return func() string {
+			q, _ := RandomQuote(rng, AnyLength)
+			return q.Text
+		}, nil
+	}
 	pool, err := wordPool(mode)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	return StreamFrom(rng, pool), nil
+}
 
-	for range wordCount {
-		words = append(words, pool[rand.IntN(len(pool))])
+// StreamFrom is like WordStream for words drawn from pool.
+func StreamFrom(rng *rand.Rand, pool []string) func() string {
+	return func() string {
+		text, _ := WordsFrom(rng, pool, streamChunk)
+		return text
 	}
+}
 
-	return strings.Join(words, " "), nil
+// PracticeText repeats each distinct word repeat times in shuffled order,
+// for drilling words that were mistyped.
+func PracticeText(rng *rand.Rand, words []string, repeat int) string {
+	words = uniqueWords(words)
+	drill := make([]string, 0, len(words)*max(repeat, 1))
+	for range max(repeat, 1) {
+		drill = append(drill, words...)
+	}
+	rng.Shuffle(len(drill), func(i, j int) {
+		drill[i], drill[j] = drill[j], drill[i]
+	})
+	return strings.Join(drill, " ")
+}
+
+// Modes lists the text modes: random common words, whole quotes and
+// programming terms.
+func Modes() []string {
+	return []string{"words", "quote", "code"}
 }
 
 func wordPool(mode string) ([]string, error) {
 	switch mode {
-	case "quote":
-		return quotePool, nil
+	case "words":
+		return wordsPool, nil
 	case "code":
 		return codePool, nil
+	case "quote":
+		return nil, errors.New("quote mode types whole quotes, not random words")
 	default:
 		return nil, errors.New("unsupported mode")
 	}
@@ -0,0 +1,105 @@
+package content
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Custom text modes, set by --text-file and --wordlist rather than --mode.
+const (
+	TextMode     = "text"     // a file typed as-is, page by page
+	WordListMode = "wordlist" // words drawn from a user's list
+)
+
+const (
+	// PageWords is how many words a page of a text file holds by default.
+	PageWords = 50
+
+	// maxCustomSize caps a text file or word list, so a wrong path such as
+	// a disk image fails fast.
+	maxCustomSize = 8 << 20
+)
+
+// ReadText reads a text file to type. Runs of whitespace, including line
+// breaks, become single spaces. name labels errors, e.g. the file path.
+func ReadText(r io.Reader, name string) (string, error) {
+	data, err := readCustom(r, name)
+	if err != nil {
+		return "", err
+	}
+	text := strings.Join(strings.Fields(data), " ")
+	if text == "" {
+		return "", fmt.Errorf("%s: no text to type", name)
+	}
+	return text, nil
+}
+
+// ReadWordList reads a newline-separated word list. Each line is one entry
+// with its whitespace normalised; blank lines and repeats are dropped.
+func ReadWordList(r io.Reader, name string) ([]string, error) {
+	data, err := readCustom(r, name)
+	if err != nil {
+		return nil, err
+	}
+	var words []string
+	for line := range strings.Lines(data) {
+		if word := strings.Join(strings.Fields(line), " "); word != "" {
+			words = append(words, word)
+		}
+	}
+	words = uniqueWords(words)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%s: no words in the list", name)
+	}
+	return words, nil
+}
+
+// readCustom reads user-supplied text, checking that it is UTF-8 without
+// control characters other than whitespace.
+func readCustom(r io.Reader, name string) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxCustomSize+1))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	if len(data) > maxCustomSize {
+		return "", fmt.Errorf("%s: larger than %d MiB", name, maxCustomSize>>20)
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff")) // editor BOM
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return "", fmt.Errorf("%s: line %d contains a NUL byte; is it a binary file?", name, 1+bytes.Count(data[:i], []byte("\n")))
+	}
+
+	line := 1
+	for i := 0; i < len(data); {
+		c, size := utf8.DecodeRune(data[i:])
+		switch {
+		case c == utf8.RuneError && size == 1:
+			return "", fmt.Errorf("%s: line %d is not valid UTF-8; convert the file to UTF-8 first", name, line)
+		case unicode.IsControl(c) && !unicode.IsSpace(c):
+			return "", fmt.Errorf("%s: line %d contains control character %U", name, line, c)
+		case c == '\n':
+			line++
+		}
+		i += size
+	}
+	return string(data), nil
+}
+
+// Pages splits text into pages of size words, so a long file is typed one
+// test at a time.
+func Pages(text string, size int) ([]string, error) {
+	if size <= 0 {
+		return nil, errors.New("page size must be greater than zero")
+	}
+	words := strings.Fields(text)
+	pages := make([]string, 0, (len(words)+size-1)/size)
+	for start := 0; start < len(words); start += size {
+		pages = append(pages, strings.Join(words[start:min(start+size, len(words))], " "))
+	}
+	return pages, nil
+}
@@ -0,0 +1,56 @@
+package content
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadTextNormalisesWhitespace(t *testing.T) {
+	text, err := ReadText(strings.NewReader("\ufeffCafé  au\tlait\r\n\n  s'il vous plaît\n"), "menu.txt")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if want := "Café au lait s'il vous plaît"; text != want {
+		t.Fatalf("got %q, want %q", text, want)
+	}
+}
+
+func TestReadTextRejectsBadFiles(t *testing.T) {
+	for _, tc := range []struct {
+		name, data, want string
+	}{
+		{"latin1", "ok\nna\xefve\n", "menu.txt: line 2 is not valid UTF-8"},
+		{"binary", "PK\x03\x04\x00", "menu.txt: line 1 contains a NUL byte"},
+		{"control", "one\ntwo\x07", "menu.txt: line 2 contains control character U+0007"},
+		{"empty", " \n\t\n", "menu.txt: no text to type"},
+	} {
+		_, err := ReadText(strings.NewReader(tc.data), "menu.txt")
+		if err == nil || !strings.HasPrefix(err.Error(), tc.want) {
+			t.Errorf("%s: got error %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestReadWordList(t *testing.T) {
+	words, err := ReadWordList(strings.NewReader("kubectl\n\n  New   York \nkubectl\nGetUserByID"), "terms.txt")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if want := []string{"kubectl", "New York", "GetUserByID"}; !slices.Equal(words, want) {
+		t.Fatalf("got %q, want %q", words, want)
+	}
+	if _, err := ReadWordList(strings.NewReader("\n\n"), "terms.txt"); err == nil {
+		t.Fatal("expected an error for an empty list")
+	}
+}
+
+func TestPages(t *testing.T) {
+	pages, err := Pages("a b c d e", 2)
+	if err != nil {
+		t.Fatalf("pages: %v", err)
+	}
+	if want := []string{"a b", "c d", "e"}; !slices.Equal(pages, want) {
+		t.Fatalf("got %q, want %q", pages, want)
+	}
+}
@@ -0,0 +1,146 @@
+package content
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Modifiers dress up generated words to read more like prose. Their names
+// are saved in history records, so personal bests are kept apart.
+const (
+	Capitals    = "capitals"    // capitalise the start of each sentence
+	Punctuation = "punctuation" // end sentences and add commas
+	Numbers     = "numbers"     // mix in numbers
+	Brackets    = "brackets"    // wrap some words in brackets
+	Quotes      = "quotes"      // wrap some words in quotation marks
+)
+
+// Modifiers lists the text modifiers in menu order.
+func Modifiers() []string {
+	return []string{Capitals, Punctuation, Numbers, Brackets, Quotes}
+}
+
+// Rates per word, roughly as in English prose.
+const (
+	sentenceEnd  = 0.12 // about eight words a sentence
+	commaRate    = 0.08
+	questionRate = 0.15 // of sentence ends
+	exclaimRate  = 0.05
+	numberRate   = 0.10
+	bracketRate  = 0.04
+	quoteRate    = 0.05
+)
+
+var brackets = [][2]string{{"(", ")"}, {"[", "]"}, {"{", "}"}}
+
+// modifier applies modifiers word by word, remembering where the sentence
+// is so a stream of chunks reads as one text.
+type modifier struct {
+	rng       *rand.Rand
+	on        map[string]bool
+	midSpeech bool // the next word starts a sentence when false
+}
+
+func newModifier(rng *rand.Rand, mods []string) *modifier {
+	m := &modifier{rng: rng, on: make(map[string]bool, len(mods))}
+	for _, mod := range mods {
+		m.on[mod] = true
+	}
+	return m
+}
+
+// apply modifies space-separated words. With end set the text finishes a
+// sentence.
+func (m *modifier) apply(text string, end bool) string {
+	words := strings.Fields(text)
+	for i, w := range words {
+		if m.on[Numbers] && m.rng.Float64() < numberRate {
+			w = m.number()
+		}
+		if m.on[Capitals] && !m.midSpeech {
+			w = capitalise(w)
+		}
+		m.midSpeech = true
+
+		switch {
+		case m.on[Brackets] && m.rng.Float64() < bracketRate:
+			b := brackets[m.rng.IntN(len(brackets))]
+			w = b[0] + w + b[1]
+		case m.on[Quotes] && m.rng.Float64() < quoteRate:
+			q := `"`
+			if m.rng.IntN(3) == 0 {
+				q = "'"
+			}
+			w = q + w + q
+		}
+
+		// Sentences end at random, and always at the end of the text.
+		switch {
+		case end && i == len(words)-1, m.rng.Float64() < sentenceEnd:
+			w += m.stop()
+			m.midSpeech = false
+		case m.on[Punctuation] && m.rng.Float64() < commaRate:
+			w += ","
+		}
+		words[i] = w
+	}
+	return strings.Join(words, " ")
+}
+
+// stop returns the mark that ends a sentence, empty without punctuation.
+func (m *modifier) stop() string {
+	if !m.on[Punctuation] {
+		return ""
+	}
+	switch r := m.rng.Float64(); {
+	case r < exclaimRate:
+		return "!"
+	case r < exclaimRate+questionRate:
+		return "?"
+	default:
+		return "."
+	}
+}
+
+// number returns a number of one to four digits, shorter ones more often.
+func (m *modifier) number() string {
+	digits := 1 + min(m.rng.IntN(5), 3)
+	if digits == 1 {
+		return strconv.Itoa(m.rng.IntN(10))
+	}
+	low := 1
+	for range digits - 1 {
+		low *= 10
+	}
+	return strconv.Itoa(low + m.rng.IntN(9*low))
+}
+
+// capitalise upper-cases the first letter of w.
+func capitalise(w string) string {
+	r, size := utf8.DecodeRuneInString(w)
+	return string(unicode.ToUpper(r)) + w[size:]
+}
+
+// Modify applies mods, any of Modifiers, to generated text. The text ends
+// a sentence.
+func Modify(rng *rand.Rand, text string, mods []string) string {
+	if len(mods) == 0 {
+		return text
+	}
+	return newModifier(rng, mods).apply(text, true)
+}
+
+// ModifyStream applies mods to each chunk of a WordStream, carrying
+// sentences over from one chunk to the next.
+func ModifyStream(rng *rand.Rand, next func() string, mods []string) func() string {
+	if len(mods) == 0 {
+		return next
+	}
+	m := newModifier(rng, mods)
+	return func() string {
+		return m.apply(next(), false)
+	}
+}
@@ -0,0 +1,54 @@
+package content
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestModifyKeepsWords(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	text, err := RandomTextWith(rng, "words", 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Modify(rng, text, nil); got != text {
+		t.Fatalf("expected no modifiers to leave the text alone, got %q", got)
+	}
+
+	got := Modify(rng, text, Modifiers())
+	words := strings.Fields(got)
+	if len(words) != 200 {
+		t.Fatalf("expected 200 words, got %d", len(words))
+	}
+	if !unicode.IsUpper([]rune(strings.TrimLeft(got, `"'([{`))[0]) {
+		t.Fatalf("expected a capital first letter, got %q", got[:20])
+	}
+	if !strings.ContainsAny(got[len(got)-1:], ".?!") {
+		t.Fatalf("expected the text to end a sentence, got %q", got[len(got)-20:])
+	}
+	for _, mark := range []string{",", ".", "(", `"`} {
+		if !strings.Contains(got, mark) {
+			t.Errorf("expected %q somewhere in 200 words", mark)
+		}
+	}
+	if !strings.ContainsFunc(got, unicode.IsDigit) {
+		t.Error("expected numbers somewhere in 200 words")
+	}
+}
+
+func TestModifyCapitalsWithoutPunctuation(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	text, err := RandomTextWith(rng, "words", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Modify(rng, text, []string{Capitals})
+	if strings.ContainsAny(got, ".,?!") {
+		t.Fatalf("expected capitals alone to add no punctuation, got %q", got)
+	}
+	if strings.ToLower(got) != strings.ToLower(text) || got == text {
+		t.Fatalf("expected only letter case to change, got %q", got)
+	}
+}
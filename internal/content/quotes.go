@@ -0,0 +1,82 @@
+package content
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+//go:embed quotes.json
+var quotesJSON []byte
+
+// Quote is a passage typed as a whole in quote mode.
+type Quote struct {
+	Text   string `json:"text"`
+	Source string `json:"source"`
+	Author string `json:"author"`
+}
+
+// Attribution credits the quote, e.g. "Jane Austen, Pride and Prejudice".
+func (q Quote) Attribution() string {
+	return q.Author + ", " + q.Source
+}
+
+// Length returns the quote's length bucket by character count, as on
+// monkeytype: short up to 100, medium up to 300, long up to 600, thicc
+// beyond.
+func (q Quote) Length() string {
+	switch n := utf8.RuneCountInString(q.Text); {
+	case n <= 100:
+		return "short"
+	case n <= 300:
+		return "medium"
+	case n <= 600:
+		return "long"
+	default:
+		return "thicc"
+	}
+}
+
+// QuoteLengths lists the length buckets, shortest first.
+func QuoteLengths() []string {
+	return []string{"short", "medium", "long", "thicc"}
+}
+
+// AnyLength picks quotes of every length.
+const AnyLength = "any"
+
+// quotes parses the embedded corpus once, normalising whitespace so each
+// word is separated by a single space.
+var quotes = sync.OnceValue(func() []Quote {
+	var qs []Quote
+	if err := json.Unmarshal(quotesJSON, &qs); err != nil {
+		panic("content: quotes.json: " + err.Error())
+	}
+	for i := range qs {
+		qs[i].Text = strings.Join(strings.Fields(qs[i].Text), " ")
+	}
+	return qs
+})
+
+// RandomQuote picks a quote of the given length bucket, or of any length
+// for AnyLength.
+func RandomQuote(rng *rand.Rand, length string) (Quote, error) {
+	if length != AnyLength && !slices.Contains(QuoteLengths(), length) {
+		return Quote{}, fmt.Errorf("unknown quote length %q (want %s or %s)", length, strings.Join(QuoteLengths(), ", "), AnyLength)
+	}
+	var pool []Quote
+	for _, q := range quotes() {
+		if length == AnyLength || q.Length() == length {
+			pool = append(pool, q)
+		}
+	}
+	if len(pool) == 0 {
+		return Quote{}, fmt.Errorf("no %s quotes", length)
+	}
+	return pool[rng.IntN(len(pool))], nil
+}
@@ -0,0 +1,62 @@
+package content
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestQuoteLength(t *testing.T) {
+	tests := []struct {
+		char  string
+		count int
+		want  string
+	}{
+		{"a", 100, "short"},
+		{"a", 101, "medium"},
+		{"a", 300, "medium"},
+		{"a", 301, "long"},
+		{"a", 600, "long"},
+		{"a", 601, "thicc"},
+		{"é", 100, "short"}, // 200 bytes
+		{"ü", 300, "medium"},
+		{"—", 600, "long"}, // 1800 bytes
+	}
+	for _, tt := range tests {
+		q := Quote{Text: strings.Repeat(tt.char, tt.count)}
+		if got := q.Length(); got != tt.want {
+			t.Errorf("%d × %q: got %s, want %s", tt.count, tt.char, got, tt.want)
+		}
+	}
+}
+
+func TestRandomQuote(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	for _, length := range QuoteLengths() {
+		for range 20 {
+			q, err := RandomQuote(rng, length)
+			if err != nil {
+				t.Fatalf("%s: %v", length, err)
+			}
+			if q.Length() != length {
+				t.Fatalf("%s: got a %s quote %q", length, q.Length(), q.Text)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for range 200 {
+		q, err := RandomQuote(rng, AnyLength)
+		if err != nil {
+			t.Fatalf("%s: %v", AnyLength, err)
+		}
+		seen[q.Length()] = true
+	}
+	if len(seen) < 2 {
+		t.Fatalf("expected quotes of several lengths for %s, got %v", AnyLength, seen)
+	}
+
+	if _, err := RandomQuote(rng, "huge"); err == nil {
+		t.Fatal("expected an unknown length to fail")
+	}
+}
@@ -0,0 +1,284 @@
+package content
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// CodebaseMode types snippets cut from a local source tree, set by
+// --code-dir rather than --mode.
+const CodebaseMode = "codebase"
+
+const (
+	maxCodeFiles    = 5000    // files read per scan, so huge trees start quickly
+	maxCodeFileSize = 1 << 20 // larger files are usually generated or data
+	maxSnippetLines = 40      // longer blocks are cut when found
+	minSnippetWords = 5       // shorter blocks are not worth a test
+)
+
+// Snippet is a function or block from a source file, with its line breaks
+// and indentation relative to its first line.
+type Snippet struct {
+	Text string
+	File string // path relative to the scanned directory
+	Line int    // line of the first row in File
+	Name string // function name, when known
+}
+
+// Attribution locates the snippet, e.g. "internal/app/app.go:42 (newModel)".
+func (s Snippet) Attribution() string {
+	loc := fmt.Sprintf("%s:%d", s.File, s.Line)
+	if s.Name != "" {
+		loc += " (" + s.Name + ")"
+	}
+	return loc
+}
+
+// codeExtensions are the non-Go files cut into blocks by indentation.
+var codeExtensions = map[string]bool{
+	".c": true, ".cc": true, ".cpp": true, ".cs": true, ".h": true, ".hpp": true,
+	".java": true, ".js": true, ".jsx": true, ".kt": true, ".lua": true, ".php": true,
+	".py": true, ".rb": true, ".rs": true, ".scala": true, ".sh": true, ".swift": true,
+	".ts": true, ".tsx": true, ".zig": true,
+}
+
+// skipDirs are directories of dependencies and build output.
+var skipDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "testdata": true, "target": true,
+	"build": true, "dist": true, "__pycache__": true,
+}
+
+// ScanCode walks root and collects its functions and blocks: every
+// function declaration of Go files, found with go/parser, and blocks that
+// start with a definition in other languages, found by indentation.
+// Hidden, dependency and build directories are skipped.
+func ScanCode(root string) ([]Snippet, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	var snippets []Snippet
+	files := 0
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // unreadable parts of the tree are skipped
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || skipDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(name)
+		if ext != ".go" && !codeExtensions[ext] {
+			return nil
+		}
+		if files++; files > maxCodeFiles {
+			return filepath.SkipAll
+		}
+		src, err := os.ReadFile(path)
+		if err != nil || len(src) > maxCodeFileSize || !utf8.Valid(src) {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		rel = filepath.ToSlash(rel)
+		if ext == ".go" {
+			snippets = append(snippets, goSnippets(rel, src)...)
+		} else {
+			snippets = append(snippets, blockSnippets(rel, src)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(snippets) == 0 {
+		return nil, fmt.Errorf("%s: no functions or blocks found in Go or other source files", root)
+	}
+	return snippets, nil
+}
+
+// goSnippets returns the function declarations of a Go file, without
+// their doc comments. Generated files and files that do not parse are
+// skipped.
+func goSnippets(file string, src []byte) []Snippet {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil || ast.IsGenerated(f) {
+		return nil
+	}
+	var out []Snippet
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		start, end := fset.Position(fn.Pos()), fset.Position(fn.End())
+		lines := strings.Split(string(src[start.Offset:end.Offset]), "\n")
+		if s, ok := newSnippet(file, start.Line, fn.Name.Name, lines); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// definition matches the first line of a function, method or class in
+// common languages, after any modifiers.
+var definition = regexp.MustCompile(`^(?:(?:export|default|public|private|protected|internal|static|final|abstract|override|async|pub(?:\([a-z]+\))?|unsafe|inline|virtual)\s+)*` +
+	`(?:def|fn|func|fun|function|class|struct|impl|interface|trait|enum|module|sub)\b`)
+
+// cFunction matches a C-style signature that opens its body on the line,
+// e.g. "int main(int argc, char **argv) {".
+var cFunction = regexp.MustCompile(`^[A-Za-z_][\w:<>,*&\s]*\([^;]*\)[^;]*\{\s*$`)
+
+// blockSnippets cuts definitions out of a file by indentation: a block is
+// its first line, every following line indented deeper or blank, and a
+// closing line at the first line's indentation such as "}" or "end".
+func blockSnippets(file string, src []byte) []Snippet {
+	lines := strings.Split(string(bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n"))), "\n")
+	var out []Snippet
+	for i := 0; i < len(lines); i++ {
+		head := strings.TrimSpace(lines[i])
+		if !definition.MatchString(head) && !cFunction.MatchString(head) {
+			continue
+		}
+		indent := indentOf(lines[i])
+		end := i + 1
+		for end < len(lines) && (strings.TrimSpace(lines[end]) == "" || indentOf(lines[end]) > indent) {
+			end++
+		}
+		if end < len(lines) && indentOf(lines[end]) == indent && isCloser(strings.TrimSpace(lines[end])) {
+			end++
+		}
+		if s, ok := newSnippet(file, i+1, "", lines[i:end]); ok {
+			out = append(out, s)
+			i = end - 1 // nested definitions are part of this block
+		}
+	}
+	return out
+}
+
+// isCloser reports whether a line ends a block opened above it.
+func isCloser(line string) bool {
+	return strings.HasPrefix(line, "}") || strings.HasPrefix(line, ")") || strings.HasPrefix(line, "]") || line == "end" || line == "fi"
+}
+
+// indentOf counts the leading whitespace of a line, a tab as one.
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// newSnippet tidies a block for typing: trailing whitespace and blank
+// lines are dropped, the indentation shared by every line is removed and
+// long blocks are cut to maxSnippetLines.
+func newSnippet(file string, line int, name string, lines []string) (Snippet, bool) {
+	var kept []string
+	for _, l := range lines {
+		if l = strings.TrimRight(l, " \t\r"); l != "" {
+			kept = append(kept, l)
+		}
+		if len(kept) == maxSnippetLines {
+			break
+		}
+	}
+	if len(kept) == 0 {
+		return Snippet{}, false
+	}
+	prefix := kept[0][:indentOf(kept[0])]
+	for _, l := range kept[1:] {
+		for !strings.HasPrefix(l, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	for i, l := range kept {
+		kept[i] = l[len(prefix):]
+	}
+	text := strings.Join(kept, "\n")
+	if len(strings.Fields(text)) < minSnippetWords {
+		return Snippet{}, false
+	}
+	return Snippet{Text: text, File: file, Line: line, Name: name}, true
+}
+
+// RandomSnippet picks a snippet and cuts it to wordCount words, ending at
+// a line break when the first lines fit. Snippets long enough to fill the
+// test are preferred. A wordCount of zero keeps the whole snippet.
+func RandomSnippet(rng *rand.Rand, snippets []Snippet, wordCount int) (Snippet, error) {
+	if len(snippets) == 0 {
+		return Snippet{}, errors.New("no code snippets to choose from")
+	}
+	pool := snippets
+	if wordCount > 0 {
+		var long []Snippet
+		for _, s := range snippets {
+			if len(strings.Fields(s.Text)) >= wordCount {
+				long = append(long, s)
+			}
+		}
+		if len(long) > 0 {
+			pool = long
+		}
+	}
+	s := pool[rng.IntN(len(pool))]
+	if wordCount > 0 {
+		s.Text = cutWords(s.Text, wordCount)
+	}
+	return s, nil
+}
+
+// SnippetStream returns a source of whole snippets for timed tests, like
+// WordStream.
+func SnippetStream(rng *rand.Rand, snippets []Snippet) (func() string, error) {
+	if len(snippets) == 0 {
+		return nil, errors.New("no code snippets to choose from")
+	}
+	return func() string {
+		return snippets[rng.IntN(len(snippets))].Text
+	}, nil
+}
+
+// cutWords keeps the first n words of text with their whitespace. It
+// stops at the last line break that keeps at most n words, or within the
+// first line if that alone is longer.
+func cutWords(text string, n int) string {
+	lines := strings.Split(text, "\n")
+	words := 0
+	for i, l := range lines {
+		w := len(strings.Fields(l))
+		if words+w > n {
+			if i > 0 {
+				return strings.Join(lines[:i], "\n")
+			}
+			fields := strings.Fields(l)
+			last := fields[n-1]
+			// Cut after the nth word, keeping the indentation before it.
+			at := 0
+			for _, f := range fields[:n-1] {
+				at = strings.Index(l[at:], f) + at + len(f)
+			}
+			at = strings.Index(l[at:], last) + at + len(last)
+			return l[:at]
+		}
+		words += w
+	}
+	return text
+}
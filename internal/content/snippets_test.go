@@ -0,0 +1,88 @@
+package content
+
+import (
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanCode(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":                   "package main\n\n// add sums.\nfunc add(a, b int) int {\n\tsum := a + b\n\treturn sum\n}\n",
+		"lib/util.py":               "import os\n\nclass Store:\n    def load(self, path):\n        if path:\n            return open(path)\n\n        return None\n",
+		"web/app.js":                "const x = 1;\nexport function double(n) {\n  const m = n * 2;\n  return m;\n}\n",
+		"node_modules/dep/index.js": "function skipped(a) {\n  return a + a + a + a;\n}\n",
+		"notes.txt":                 "def not code at all, just a text file\n",
+	}
+	for name, src := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snippets, err := ScanCode(root)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	got := make(map[string]Snippet)
+	for _, s := range snippets {
+		got[s.File] = s
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected snippets from 3 files, got %+v", snippets)
+	}
+	if s := got["main.go"]; s.Name != "add" || s.Line != 4 || s.Text != "func add(a, b int) int {\n\tsum := a + b\n\treturn sum\n}" {
+		t.Fatalf("unexpected Go snippet %+v", s)
+	}
+	if s := got["lib/util.py"]; s.Line != 3 || s.Text != "class Store:\n    def load(self, path):\n        if path:\n            return open(path)\n        return None" {
+		t.Fatalf("unexpected Python snippet %+v", s)
+	}
+	if s := got["web/app.js"]; !strings.HasPrefix(s.Text, "export function double(n) {\n  const") || !strings.HasSuffix(s.Text, "\n}") {
+		t.Fatalf("unexpected JavaScript snippet %+v", s)
+	}
+
+	if _, err := ScanCode(t.TempDir()); err == nil {
+		t.Fatal("expected an error for a tree without code")
+	}
+}
+
+func TestRandomSnippetLimitsWords(t *testing.T) {
+	code := Snippet{Text: "func f() {\n\tx := 1\n\treturn x\n}"}
+	rng := rand.New(rand.NewPCG(1, 1))
+	for _, tc := range []struct {
+		words int
+		want  string
+	}{
+		{0, code.Text},
+		{6, "func f() {\n\tx := 1"},
+		{2, "func f()"},
+	} {
+		s, err := RandomSnippet(rng, []Snippet{code}, tc.words)
+		if err != nil {
+			t.Fatalf("random: %v", err)
+		}
+		if s.Text != tc.want {
+			t.Errorf("%d words: got %q, want %q", tc.words, s.Text, tc.want)
+		}
+	}
+}
+
+func TestSnippetStream(t *testing.T) {
+	if _, err := SnippetStream(rand.New(rand.NewPCG(1, 1)), nil); err == nil {
+		t.Fatal("expected an error without snippets")
+	}
+	next, err := SnippetStream(rand.New(rand.NewPCG(1, 1)), []Snippet{{Text: "func f() {}"}})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if got := next(); got != "func f() {}" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,98 @@
+package engine
+
+// ErrorCounts classifies the differences between typed and expected text.
+type ErrorCounts struct {
+	Substitutions  int // wrong character in place of the expected one
+	Insertions     int // extra character that was not expected
+	Omissions      int // expected character that was skipped
+	Transpositions int // two adjacent characters swapped
+}
+
+// Total returns the number of errors of any kind.
+func (c ErrorCounts) Total() int {
+	return c.Substitutions + c.Insertions + c.Omissions + c.Transpositions
+}
+
+func (c *ErrorCounts) add(o ErrorCounts) {
+	c.Substitutions += o.Substitutions
+	c.Insertions += o.Insertions
+	c.Omissions += o.Omissions
+	c.Transpositions += o.Transpositions
+}
+
+// AlignWord aligns typed against expected with the fewest edits (optimal
+// string alignment) and returns how many characters matched and how the
+// rest differ. A skipped or extra character therefore costs one error
+// instead of shifting everything after it. When partial is true the word
+// is still being typed, so untyped characters at the end of expected are
+// not counted as omissions.
+func AlignWord(expected, typed []rune, partial bool) (matches int, errs ErrorCounts) {
+	n, m := len(typed), len(expected)
+
+	// d[i][j] is the cost of aligning typed[:i] with expected[:j].
+	d := make([][]int, n+1)
+	for i := range d {
+		d[i] = make([]int, m+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= m; j++ {
+		d[0][j] = j
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			cost := 1
+			if typed[i-1] == expected[j-1] {
+				cost = 0
+			}
+			best := d[i-1][j-1] + cost
+			best = min(best, d[i-1][j]+1, d[i][j-1]+1)
+			if isSwap(expected, typed, i, j) {
+				best = min(best, d[i-2][j-2]+1)
+			}
+			d[i][j] = best
+		}
+	}
+
+	// A partial word ends wherever typed[:n] lines up best; ties go to the
+	// longer prefix so "hex" against "hello" reads as one substitution.
+	end := m
+	if partial {
+		end = 0
+		for j := 1; j <= m; j++ {
+			if d[n][j] <= d[n][end] {
+				end = j
+			}
+		}
+	}
+
+	// Walk back, preferring match, transposition, substitution,
+	// insertion, omission in that order.
+	i, j := n, end
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && typed[i-1] == expected[j-1] && d[i][j] == d[i-1][j-1]:
+			matches++
+			i, j = i-1, j-1
+		case isSwap(expected, typed, i, j) && d[i][j] == d[i-2][j-2]+1:
+			errs.Transpositions++
+			i, j = i-2, j-2
+		case i > 0 && j > 0 && d[i][j] == d[i-1][j-1]+1:
+			errs.Substitutions++
+			i, j = i-1, j-1
+		case i > 0 && d[i][j] == d[i-1][j]+1:
+			errs.Insertions++
+			i--
+		default:
+			errs.Omissions++
+			j--
+		}
+	}
+	return matches, errs
+}
+
+// isSwap reports whether typed[i-2:i] is expected[j-2:j] reversed.
+func isSwap(expected, typed []rune, i, j int) bool {
+	return i > 1 && j > 1 &&
+		typed[i-1] == expected[j-2] && typed[i-2] == expected[j-1] &&
+		typed[i-1] != typed[i-2]
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EventKind says what a keystroke did to the session.
+type EventKind string
+
+const (
+	EventRune      EventKind = "rune"
+	EventBackspace EventKind = "backspace"
+)
+
+// Event is one keystroke. For EventRune, Typed is the key and Expected the
+// target rune at Pos (zero for letters typed past the end of a word). For
+// EventBackspace, Typed is the rune that was erased from Pos and Correct
+// says whether it had been right.
+type Event struct {
+	Kind     EventKind
+	Pos      int
+	Word     int // index of the target word
+	Typed    rune
+	Expected rune
+	Correct  bool
+	At       time.Duration // since the first keystroke, from the monotonic clock
+}
+
+// eventJSON is the stable on-disk shape of an Event. Field names and
+// order must not change; add new fields at the end.
+type eventJSON struct {
+	T        int64     `json:"t_us"`
+	Kind     EventKind `json:"kind"`
+	Pos      int       `json:"pos"`
+	Typed    string    `json:"typed,omitempty"`
+	Expected string    `json:"expected,omitempty"`
+	Correct  bool      `json:"correct"`
+	Word     int       `json:"word,omitempty"`
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal(eventJSON{
+		T:        e.At.Microseconds(),
+		Kind:     e.Kind,
+		Pos:      e.Pos,
+		Typed:    runeString(e.Typed),
+		Expected: runeString(e.Expected),
+		Correct:  e.Correct,
+		Word:     e.Word,
+	})
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var raw eventJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	typed, err := singleRune(raw.Typed)
+	if err != nil {
+		return fmt.Errorf("typed: %w", err)
+	}
+	expected, err := singleRune(raw.Expected)
+	if err != nil {
+		return fmt.Errorf("expected: %w", err)
+	}
+	*e = Event{
+		Kind:     raw.Kind,
+		Pos:      raw.Pos,
+		Typed:    typed,
+		Expected: expected,
+		Correct:  raw.Correct,
+		Word:     raw.Word,
+		At:       time.Duration(raw.T) * time.Microsecond,
+	}
+	return nil
+}
+
+func runeString(r rune) string {
+	if r == 0 {
+		return ""
+	}
+	return string(r)
+}
+
+func singleRune(s string) (rune, error) {
+	runes := []rune(s)
+	switch len(runes) {
+	case 0:
+		return 0, nil
+	case 1:
+		return runes[0], nil
+	default:
+		return 0, fmt.Errorf("want a single character, got %q", s)
+	}
+}
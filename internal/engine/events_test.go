@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSessionRecordsEvents(t *testing.T) {
+	start := time.Now()
+	s := NewSession("ab", 0)
+
+	s.ApplyRune('a', start)
+	s.ApplyRune('x', start.Add(100*time.Millisecond))
+	s.Backspace(start.Add(250 * time.Millisecond))
+	s.ApplyRune('b', start.Add(400*time.Millisecond))
+
+	events := s.Events()
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(events))
+	}
+	bs := events[2]
+	if bs.Kind != EventBackspace || bs.Pos != 1 || bs.Typed != 'x' || bs.Correct {
+		t.Fatalf("unexpected backspace event: %+v", bs)
+	}
+	if events[1].Expected != 'b' || events[1].Correct {
+		t.Fatalf("unexpected wrong-key event: %+v", events[1])
+	}
+	if events[3].At != 400*time.Millisecond {
+		t.Fatalf("expected last event at 400ms, got %s", events[3].At)
+	}
+}
+
+func TestEventJSONIsStable(t *testing.T) {
+	e := Event{Kind: EventRune, Pos: 3, Typed: 'x', Expected: 'é', At: 1500 * time.Microsecond}
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"t_us":1500,"kind":"rune","pos":3,"typed":"x","expected":"é","correct":false}`
+	if string(data) != want {
+		t.Fatalf("expected %s, got %s", want, data)
+	}
+
+	var back Event
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back != e {
+		t.Fatalf("round trip mismatch: %+v != %+v", back, e)
+	}
+}
@@ -2,7 +2,12 @@ package engine
 
 import "time"
 
+// Metrics summarises a session. WPM, Accuracy, Errors, TotalTyped and
+// Correct describe the final text, after Backspace corrections. The
+// keystroke fields below count every key ever pressed.
 type Metrics struct {
+	Kind         TestKind
+	TimeLimit    time.Duration
 	WPM          float64
 	RawWPM       float64
 	Accuracy     float64
@@ -15,4 +20,17 @@ type Metrics struct {
 	Completed    bool
 	TimedOut     bool
 	Cancelled    bool
+
+	Keystrokes        int     // character keys pressed, including erased ones
+	RawAccuracy       float64 // correct keystrokes / Keystrokes
+	CorrectedErrors   int     // wrong characters later erased
+	UncorrectedErrors int     // wrong characters left in the final text
+	Backspaces        int
+
+	// Errors left in the final text, by kind. Their total equals Errors.
+	ErrorCounts
+
+	Samples     []Sample // speed over time, one per sample interval
+	Consistency float64  // 0-100, higher means steadier raw speed
+	BurstWPM    float64  // fastest raw WPM over one sample interval
 }
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultSampleInterval is the bucket width used for the speed series.
+const DefaultSampleInterval = time.Second
+
+// Sample is the typing speed over one bucket of the test.
+type Sample struct {
+	At     time.Duration // end of the bucket, since the first keystroke
+	WPM    float64       // net WPM from the start of the test up to At
+	RawWPM float64       // raw WPM of the keystrokes inside the bucket
+	Errors int           // wrong keystrokes inside the bucket
+}
+
+// Samples splits the event log into interval-wide buckets up to elapsed.
+// A trailing bucket shorter than half an interval is folded into the one
+// before it so a last stray keystroke does not skew the series.
+func Samples(events []Event, elapsed, interval time.Duration) []Sample {
+	if interval <= 0 {
+		interval = DefaultSampleInterval
+	}
+	if elapsed <= 0 {
+		return nil
+	}
+
+	var ends []time.Duration
+	for end := interval; end < elapsed; end += interval {
+		ends = append(ends, end)
+	}
+	if n := len(ends); n > 0 && elapsed-ends[n-1] < interval/2 {
+		ends = ends[:n-1]
+	}
+	ends = append(ends, elapsed)
+
+	samples := make([]Sample, 0, len(ends))
+	net := 0 // correct characters still in the text
+	next := 0
+	start := time.Duration(0)
+	for _, end := range ends {
+		keys, errors := 0, 0
+		for ; next < len(events) && (events[next].At < end || end == elapsed); next++ {
+			e := events[next]
+			switch {
+			case e.Kind == EventRune:
+				keys++
+				if e.Correct {
+					net++
+				} else {
+					errors++
+				}
+			case e.Correct:
+				net-- // erased a correct character
+			}
+		}
+		samples = append(samples, Sample{
+			At:     end,
+			WPM:    CalculateNetWPM(net, end),
+			RawWPM: CalculateRawWPM(keys, end-start),
+			Errors: errors,
+		})
+		start = end
+	}
+	return samples
+}
+
+// Consistency scores how steady the raw speed was, from 0 to 100. It maps
+// the coefficient of variation of the per-bucket raw WPM through
+// 1 - tanh(cv + cv³/3 + cv⁵/5), as monkeytype does.
+func Consistency(samples []Sample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range samples {
+		sum += s.RawWPM
+	}
+	mean := sum / float64(len(samples))
+	if mean <= 0 {
+		return 0
+	}
+	var sq float64
+	for _, s := range samples {
+		sq += (s.RawWPM - mean) * (s.RawWPM - mean)
+	}
+	cv := math.Sqrt(sq/float64(len(samples))) / mean
+	return 100 * (1 - math.Tanh(cv+math.Pow(cv, 3)/3+math.Pow(cv, 5)/5))
+}
+
+// BurstWPM returns the fastest per-bucket raw WPM.
+func BurstWPM(samples []Sample) float64 {
+	best := 0.0
+	for _, s := range samples {
+		best = max(best, s.RawWPM)
+	}
+	return best
+}
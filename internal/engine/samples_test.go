@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSamplesBucketEvents(t *testing.T) {
+	events := []Event{
+		{Kind: EventRune, Correct: true, At: 100 * time.Millisecond},
+		{Kind: EventRune, Correct: false, At: 600 * time.Millisecond},
+		{Kind: EventBackspace, Correct: false, At: 900 * time.Millisecond},
+		{Kind: EventRune, Correct: true, At: 1200 * time.Millisecond},
+		{Kind: EventRune, Correct: true, At: 1900 * time.Millisecond},
+	}
+	samples := Samples(events, 2*time.Second, time.Second)
+	if len(samples) != 2 {
+		t.Fatalf("expected 2 samples, got %d", len(samples))
+	}
+	if samples[0].Errors != 1 || samples[1].Errors != 0 {
+		t.Fatalf("unexpected errors per bucket: %+v", samples)
+	}
+	// 2 keys in one second => (2/5)*60 = 24 raw WPM
+	if math.Abs(samples[0].RawWPM-24) > 0.001 {
+		t.Fatalf("expected 24 raw WPM, got %.3f", samples[0].RawWPM)
+	}
+	// 3 correct characters after two seconds => (3/5)*30 = 18 net WPM
+	if math.Abs(samples[1].WPM-18) > 0.001 {
+		t.Fatalf("expected 18 net WPM, got %.3f", samples[1].WPM)
+	}
+}
+
+func TestSamplesFoldShortTail(t *testing.T) {
+	events := []Event{{Kind: EventRune, Correct: true, At: 2100 * time.Millisecond}}
+	samples := Samples(events, 2200*time.Millisecond, time.Second)
+	if len(samples) != 2 || samples[1].At != 2200*time.Millisecond {
+		t.Fatalf("expected the 200ms tail folded into the second bucket, got %+v", samples)
+	}
+}
+
+func TestConsistency(t *testing.T) {
+	steady := []Sample{{RawWPM: 60}, {RawWPM: 60}, {RawWPM: 60}}
+	if c := Consistency(steady); math.Abs(c-100) > 0.001 {
+		t.Fatalf("expected 100 for a steady pace, got %.3f", c)
+	}
+	bursty := []Sample{{RawWPM: 120}, {RawWPM: 0}, {RawWPM: 120}, {RawWPM: 0}}
+	if c := Consistency(bursty); c > 30 {
+		t.Fatalf("expected low consistency for a bursty pace, got %.3f", c)
+	}
+	if b := BurstWPM(bursty); b != 120 {
+		t.Fatalf("expected burst 120, got %.1f", b)
+	}
+}
@@ -44,27 +44,27 @@ func TestCalculateAccuracyZeroTotal(t *testing.T) {
 	}
 }
 
-func TestCountCorrectWords(t *testing.T) {
-	target := []rune("hello world test")
-	input := []rune("hello wxrld test")
-	correct, total := CountCorrectWords(target, input)
-	if total != 3 {
-		t.Fatalf("expected 3 total words, got %d", total)
+func TestAlignWordClassifiesErrors(t *testing.T) {
+	cases := []struct {
+		expected, typed string
+		partial         bool
+		matches         int
+		errs            ErrorCounts
+	}{
+		{"hello", "hello", false, 5, ErrorCounts{}},
+		{"hello", "hxllo", false, 4, ErrorCounts{Substitutions: 1}},
+		{"hello", "helo", false, 4, ErrorCounts{Omissions: 1}},
+		{"hello", "helllo", false, 5, ErrorCounts{Insertions: 1}},
+		{"hello", "hlelo", false, 3, ErrorCounts{Transpositions: 1}},
+		{"hello", "he", false, 2, ErrorCounts{Omissions: 3}},
+		{"hello", "he", true, 2, ErrorCounts{}},
+		{"hello", "hex", true, 2, ErrorCounts{Substitutions: 1}},
 	}
-	if correct != 2 {
-		t.Fatalf("expected 2 correct words (hello, test), got %d", correct)
-	}
-}
-
-func TestCountCorrectWordsPartialInput(t *testing.T) {
-	target := []rune("one two three")
-	input := []rune("one tw")
-	correct, total := CountCorrectWords(target, input)
-	// "one" is complete+correct, "tw" is partial (counts as attempted but incomplete)
-	if correct != 1 {
-		t.Fatalf("expected 1 correct word, got %d", correct)
-	}
-	if total != 2 {
-		t.Fatalf("expected 2 total words attempted, got %d", total)
+	for _, tc := range cases {
+		matches, errs := AlignWord([]rune(tc.expected), []rune(tc.typed), tc.partial)
+		if matches != tc.matches || errs != tc.errs {
+			t.Fatalf("%q vs %q (partial %v): expected %d matches %+v, got %d %+v",
+				tc.typed, tc.expected, tc.partial, tc.matches, tc.errs, matches, errs)
+		}
 	}
 }
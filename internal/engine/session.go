@@ -1,37 +1,186 @@
 package engine
 
-import "time"
+import (
+	"slices"
+	"time"
+	"unicode"
+)
 
+// TestKind tells fixed-length tests apart from timed ones.
+type TestKind string
+
+const (
+	KindWords TestKind = "words" // type a fixed text; an optional time limit cuts it short
+	KindTime  TestKind = "time"  // type endless text until the time limit
+)
+
+// lookahead is how many untyped runes a timed session keeps buffered.
+const lookahead = 200
+
+// maxOverflow caps how many extra letters can be typed past a word's end.
+const maxOverflow = 20
+
+// span is a word's [start, end) range in the target.
+type span struct {
+	start, end int
+}
+
+// defaultIndentUnit is how many spaces a Tab stands for when the target
+// has no space-indented lines.
+const defaultIndentUnit = 4
+
+// Session tracks a test word by word. Letters fill the current word
+// (extra letters overflow it), and a space moves on to the next word even
+// if letters were missed, so one mistake never shifts the rest of the text.
+// In multi-line targets Enter ends a line in the same way, and the
+// indentation after a line break is typed with Tab or skipped.
 type Session struct {
-	target       []rune
-	input        []rune
-	cursor       int
-	started      bool
-	startTime    time.Time
-	endTime      time.Time
-	timeLimit    time.Duration
-	totalTyped   int
-	correctTyped int
-	errors       int
+	kind      TestKind
+	source    func() string // grows target in timed sessions
+	target    []rune
+	words     []span
+	typed     [][]rune // typed[i] is the input for words[i]; the last entry is the current word
+	lead      [][]rune // lead[i] is the indentation typed before words[i], one rune per target rune
+	ends      []rune   // ends[i] is the key that finished words[i], space or Enter
+	done      bool     // the last word was finished
+	doneSpace bool     // ...by pressing space rather than typing it out
+
+	autoIndent bool // indentation after a line break is skipped, as editors do
+	indentUnit int  // spaces one Tab stands for; zero until a space-indented line is seen
+
+	started   bool
+	startTime time.Time
+	endTime   time.Time
+	timeLimit time.Duration
+	events    []Event
+	interval  time.Duration // sample bucket width; zero means DefaultSampleInterval
+
+	// Keystroke totals. Backspace never decrements these.
+	keystrokes      int
+	keysCorrect     int
+	correctedErrors int
+	backspaces      int
 }
 
 func NewSession(target string, timeLimit time.Duration) *Session {
-	return &Session{
-		target:    []rune(target),
+	s := &Session{
+		kind:      KindWords,
 		timeLimit: timeLimit,
 	}
+	s.appendTarget([]rune(target))
+	return s
+}
+
+// NewTimedSession starts a time test. The target is pulled from next and
+// keeps growing as the cursor nears its end, so it never runs out.
+func NewTimedSession(next func() string, timeLimit time.Duration) *Session {
+	s := &Session{
+		kind:      KindTime,
+		source:    next,
+		timeLimit: timeLimit,
+	}
+	s.refill()
+	return s
+}
+
+// appendTarget adds text to the target and indexes its words. Chunks of
+// multi-line text are joined by a line break.
+func (s *Session) appendTarget(text []rune) {
+	from := len(s.target)
+	if from > 0 && len(text) > 0 {
+		sep := ' '
+		if slices.Contains(text, '\n') {
+			sep = '\n'
+		}
+		s.target = append(s.target, sep)
+		from++
+	}
+	s.target = append(s.target, text...)
+
+	// The indent unit is the shallowest indentation made of spaces.
+	for i := from; i < len(s.target); i++ {
+		if i > 0 && s.target[i-1] != '\n' || s.target[i] != ' ' {
+			continue
+		}
+		n := 0
+		for i+n < len(s.target) && s.target[i+n] == ' ' {
+			n++
+		}
+		if s.indentUnit == 0 || n < s.indentUnit {
+			s.indentUnit = n
+		}
+	}
+
+	start := -1
+	for i := from; i <= len(s.target); i++ {
+		inWord := i < len(s.target) && !unicode.IsSpace(s.target[i])
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			s.words = append(s.words, span{start, i})
+			start = -1
+		}
+	}
+	if len(s.typed) == 0 && len(s.words) > 0 {
+		s.typed, s.lead = [][]rune{nil}, [][]rune{nil}
+	}
+}
+
+// refill appends text from the source until lookahead runes are buffered.
+func (s *Session) refill() {
+	if s.source == nil {
+		return
+	}
+	for len(s.target)-s.Cursor() < lookahead {
+		chunk := s.source()
+		if chunk == "" {
+			return
+		}
+		s.appendTarget([]rune(chunk))
+	}
+}
+
+func (s *Session) Kind() TestKind {
+	return s.kind
 }
 
 func (s *Session) Target() []rune {
 	return s.target
 }
 
+// Input returns the typed words joined by single spaces.
 func (s *Session) Input() []rune {
-	return s.input
+	var out []rune
+	for i, w := range s.typed {
+		if i > 0 {
+			out = append(out, ' ')
+		}
+		out = append(out, w...)
+	}
+	return out
 }
 
+// Cursor returns the target index of the next expected rune. Overflowing
+// letters leave it at the end of the current word.
 func (s *Session) Cursor() int {
-	return s.cursor
+	if len(s.words) == 0 {
+		return len(s.target)
+	}
+	idx := s.current()
+	w := s.words[idx]
+	if s.done {
+		return len(s.target)
+	}
+	if len(s.typed[idx]) == 0 {
+		return w.start - len(s.pendingIndent(idx))
+	}
+	return w.start + min(len(s.typed[idx]), w.end-w.start)
+}
+
+// current returns the index of the word being typed.
+func (s *Session) current() int {
+	return len(s.typed) - 1
 }
 
 func (s *Session) Started() bool {
@@ -46,67 +195,345 @@ func (s *Session) TimeLimit() time.Duration {
 	return s.timeLimit
 }
 
+// SetSampleInterval sets the bucket width of Metrics.Samples.
+func (s *Session) SetSampleInterval(d time.Duration) {
+	s.interval = d
+}
+
+// SetAutoIndent makes the cursor skip the indentation after a line break,
+// as editors do. Skipped indentation does not count as typed.
+func (s *Session) SetAutoIndent(on bool) {
+	s.autoIndent = on
+}
+
+// Multiline reports whether the target has line breaks or tabs, which are
+// typed with Enter and Tab.
+func (s *Session) Multiline() bool {
+	return slices.ContainsFunc(s.target, func(r rune) bool { return r == '\n' || r == '\t' })
+}
+
+// IndentUnit returns how many spaces one Tab stands for in indentation.
+func (s *Session) IndentUnit() int {
+	if s.indentUnit == 0 {
+		return defaultIndentUnit
+	}
+	return s.indentUnit
+}
+
+// lineBreak returns the key that ends words[i]: Enter when a line break
+// follows it, otherwise space.
+func (s *Session) lineBreak(i int) rune {
+	if i+1 < len(s.words) && slices.Contains(s.target[s.words[i].end:s.words[i+1].start], '\n') {
+		return '\n'
+	}
+	return ' '
+}
+
+// indent returns the whitespace between the last line break before
+// words[i] and the word; nil when the word does not start a line.
+func (s *Session) indent(i int) []rune {
+	if i == 0 {
+		return nil
+	}
+	sep := s.target[s.words[i-1].end:s.words[i].start]
+	nl := slices.Index(sep, '\n')
+	if nl < 0 {
+		return nil
+	}
+	last := nl
+	for j := nl; j < len(sep); j++ {
+		if sep[j] == '\n' {
+			last = j
+		}
+	}
+	return sep[last+1:]
+}
+
+// pendingIndent returns the indentation still to type before words[i].
+func (s *Session) pendingIndent(i int) []rune {
+	if s.autoIndent {
+		return nil
+	}
+	return s.indent(i)[len(s.lead[i]):]
+}
+
+// Events returns the keystroke log in the order keys were pressed.
+// Keys ignored after completion or the time limit are not logged.
+func (s *Session) Events() []Event {
+	return s.events
+}
+
+func (s *Session) record(e Event, now time.Time) {
+	e.At = now.Sub(s.startTime)
+	if e.At < 0 {
+		e.At = 0
+	}
+	s.events = append(s.events, e)
+}
+
+// WordState is one target word and what has been typed for it.
+type WordState struct {
+	Start     int    // index of the word in Target
+	Target    []rune // expected letters
+	Typed     []rune // letters typed so far, possibly longer than Target
+	Sep       []rune // whitespace after the word in Target; empty for the last word
+	Committed bool   // the user has moved past this word
+
+	Indent []rune // indentation before a word that starts a line
+	Lead   []rune // indentation typed so far, one rune per Indent rune
+	Ended  rune   // key that finished the word, space or Enter; zero while open
+}
+
+// Words returns every target word with its input. Words after the
+// current one have no input yet.
+func (s *Session) Words() []WordState {
+	out := make([]WordState, len(s.words))
+	cur := s.current()
+	for i, w := range s.words {
+		sepEnd := len(s.target)
+		if i+1 < len(s.words) {
+			sepEnd = s.words[i+1].start
+		}
+		ws := WordState{
+			Start:  w.start,
+			Target: s.target[w.start:w.end],
+			Sep:    s.target[w.end:sepEnd],
+		}
+		ws.Indent = s.indent(i)
+		if i <= cur {
+			ws.Typed = s.typed[i]
+			ws.Committed = i < cur || s.done
+			ws.Lead = s.lead[i]
+			if s.autoIndent {
+				ws.Lead = ws.Indent
+			}
+		}
+		if i < len(s.ends) {
+			ws.Ended = s.ends[i]
+		}
+		out[i] = ws
+	}
+	return out
+}
+
+// MissedWord is a finished word that was not typed exactly.
+type MissedWord struct {
+	Expected string
+	Typed    string
+}
+
+// MissedWords returns the words the user moved past with mistakes, in
+// order. A word still being typed is not included.
+func (s *Session) MissedWords() []MissedWord {
+	var missed []MissedWord
+	for _, w := range s.Words() {
+		if w.Committed && string(w.Typed) != string(w.Target) {
+			missed = append(missed, MissedWord{Expected: string(w.Target), Typed: string(w.Typed)})
+		}
+	}
+	return missed
+}
+
+// CurrentWord returns the index of the word being typed.
+func (s *Session) CurrentWord() int {
+	return max(s.current(), 0)
+}
+
 // ApplyRune types a character and returns true if it was correct.
+// A space or Enter ('\n') finishes the current word and moves to the next
+// one; it is right when it matches the whitespace after the word, and is
+// ignored at the start of a word. Before a word that starts a line, Tab
+// ('\t') and space type the indentation. Keystrokes after completion or
+// the time limit are ignored.
 func (s *Session) ApplyRune(ch rune, now time.Time) bool {
-	if s.IsCompleted() {
+	if s.IsCompleted() || s.IsTimedOut(now) || len(s.words) == 0 {
 		return false
 	}
-	if !s.started {
-		s.started = true
-		s.startTime = now
+
+	idx := s.current()
+	w := s.words[idx]
+	word := s.target[w.start:w.end]
+	typed := s.typed[idx]
+
+	if indent := s.pendingIndent(idx); len(indent) > 0 && len(typed) == 0 && (ch == '\t' || ch == ' ') {
+		return s.typeIndent(ch, indent, now)
 	}
-	if s.cursor >= len(s.target) {
-		return false
+
+	if ch == ' ' || ch == '\n' {
+		if len(typed) == 0 {
+			return false
+		}
+		if s.kind == KindTime && idx == len(s.words)-1 {
+			// refill keeps text ahead of the cursor, so the source has
+			// run dry; there is no next word and the clock ends the test.
+			return false
+		}
+		s.start(now)
+		// The key is right if the word was typed out in full and it
+		// matches the line break, if any; pressing it early skips the
+		// remaining letters. The last word may end with either.
+		expected := s.lineBreak(idx)
+		switch {
+		case len(typed) < len(word):
+			expected = word[len(typed)]
+		case idx == len(s.words)-1:
+			expected = ch
+		}
+		correct := expected == ch
+		s.keystroke(correct)
+		s.record(Event{Kind: EventRune, Pos: s.Cursor(), Word: idx, Typed: ch, Expected: expected, Correct: correct}, now)
+
+		s.ends = append(s.ends, ch)
+		if idx == len(s.words)-1 {
+			s.finish(now)
+			s.doneSpace = s.done
+		} else {
+			s.typed = append(s.typed, nil)
+			s.lead = append(s.lead, nil)
+		}
+		s.refill()
+		return correct
 	}
 
-	expected := s.target[s.cursor]
-	s.totalTyped++
+	if len(typed) >= len(word)+maxOverflow {
+		return false
+	}
+	s.start(now)
+	var expected rune
+	if len(typed) < len(word) {
+		expected = word[len(typed)]
+	}
 	correct := ch == expected
+	s.keystroke(correct)
+	s.record(Event{Kind: EventRune, Pos: w.start + len(typed), Word: idx, Typed: ch, Expected: expected, Correct: correct}, now)
+
+	s.typed[idx] = append(typed, ch)
+	if idx == len(s.words)-1 && string(s.typed[idx]) == string(word) {
+		s.finish(now)
+	}
+	s.refill()
+	return correct
+}
+
+// typeIndent types the indentation before the current word. Tab matches a
+// tab or one indent unit of spaces; any other key stands for one rune of
+// indentation, so a wrong key never shifts the rest of the line.
+func (s *Session) typeIndent(ch rune, indent []rune, now time.Time) bool {
+	s.start(now)
+	idx := s.current()
+	n := 1
+	if ch == '\t' && indent[0] == ' ' {
+		for n < min(s.IndentUnit(), len(indent)) && indent[n] == ' ' {
+			n++
+		}
+	}
+	correct := ch == indent[0] || ch == '\t'
+	s.keystroke(correct)
+	s.record(Event{Kind: EventRune, Pos: s.Cursor(), Word: idx, Typed: ch, Expected: indent[0], Correct: correct}, now)
 	if correct {
-		s.correctTyped++
+		s.lead[idx] = append(s.lead[idx], indent[:n]...)
 	} else {
-		s.errors++
+		s.lead[idx] = append(s.lead[idx], ch)
 	}
+	return correct
+}
 
-	if s.cursor < len(s.input) {
-		s.input[s.cursor] = ch
-	} else {
-		s.input = append(s.input, ch)
+func (s *Session) start(now time.Time) {
+	if !s.started {
+		s.started = true
+		s.startTime = now
 	}
-	s.cursor++
-	if s.IsCompleted() {
-		s.endTime = now
+}
+
+func (s *Session) keystroke(correct bool) {
+	s.keystrokes++
+	if correct {
+		s.keysCorrect++
 	}
-	return correct
 }
 
-func (s *Session) Backspace() {
-	if s.cursor == 0 {
+func (s *Session) finish(now time.Time) {
+	if s.kind == KindTime {
 		return
 	}
-	s.cursor--
+	s.done = true
+	s.endTime = now
+}
 
-	// Undo the scoring for the character we're erasing.
-	// This prevents inflated totalTyped/errors when the user corrects mistakes.
-	if s.cursor < len(s.input) {
-		typed := s.input[s.cursor]
-		expected := s.target[s.cursor]
-		s.totalTyped--
-		if typed == expected {
-			s.correctTyped--
-		} else {
-			s.errors--
+// Backspace erases the last typed character. At the start of a word it
+// steps back into the previous word.
+func (s *Session) Backspace(now time.Time) {
+	if !s.started || s.IsTimedOut(now) || len(s.words) == 0 {
+		return
+	}
+
+	idx := s.current()
+	w := s.words[idx]
+	typed := s.typed[idx]
+
+	switch {
+	case s.doneSpace:
+		// The last word was finished with a space; undo the space first.
+		s.done, s.doneSpace = false, false
+		s.eraseSpace(idx, now)
+		s.ends = s.ends[:idx]
+	case len(typed) == 0 && len(s.lead[idx]) > 0:
+		lead := s.lead[idx]
+		pos := len(lead) - 1
+		expected := s.indent(idx)[pos]
+		s.backspaced(Event{Kind: EventBackspace, Pos: w.start - len(s.indent(idx)) + pos, Word: idx, Typed: lead[pos], Expected: expected, Correct: lead[pos] == expected}, now)
+		s.lead[idx] = lead[:pos]
+	case len(typed) > 0:
+		s.done = false
+		pos := len(typed) - 1
+		var expected rune
+		if pos < w.end-w.start {
+			expected = s.target[w.start+pos]
 		}
+		erased := typed[pos]
+		s.backspaced(Event{Kind: EventBackspace, Pos: w.start + pos, Word: idx, Typed: erased, Expected: expected, Correct: erased == expected}, now)
+		s.typed[idx] = typed[:pos]
+	case idx > 0:
+		s.typed, s.lead = s.typed[:idx], s.lead[:idx]
+		s.eraseSpace(idx-1, now)
+		s.ends = s.ends[:idx-1]
+	default:
+		return
 	}
+	s.endTime = time.Time{}
+}
 
-	s.input = s.input[:s.cursor]
-	if !s.endTime.IsZero() {
-		s.endTime = time.Time{}
+// eraseSpace logs the removal of the space or Enter that ended word idx.
+func (s *Session) eraseSpace(idx int, now time.Time) {
+	w := s.words[idx]
+	typed := s.typed[idx]
+	ended := s.ends[idx]
+	expected := s.lineBreak(idx)
+	switch {
+	case len(typed) < w.end-w.start:
+		expected = s.target[w.start+len(typed)]
+	case idx == len(s.words)-1:
+		expected = ended
 	}
+	pos := w.start + min(len(typed), w.end-w.start)
+	s.backspaced(Event{Kind: EventBackspace, Pos: pos, Word: idx, Typed: ended, Expected: expected, Correct: expected == ended}, now)
 }
 
+func (s *Session) backspaced(e Event, now time.Time) {
+	s.backspaces++
+	if !e.Correct {
+		s.correctedErrors++
+	}
+	s.record(e, now)
+}
+
+// IsCompleted reports whether the whole target was typed. Timed sessions
+// only end by timing out.
 func (s *Session) IsCompleted() bool {
-	return s.cursor >= len(s.target)
+	if s.kind == KindTime {
+		return false
+	}
+	return s.done || len(s.words) == 0
 }
 
 func (s *Session) IsTimedOut(now time.Time) bool {
@@ -127,24 +554,86 @@ func (s *Session) Elapsed(now time.Time) time.Duration {
 	if elapsed < 0 {
 		return 0
 	}
+	if s.timeLimit > 0 && elapsed > s.timeLimit {
+		return s.timeLimit
+	}
 	return elapsed
 }
 
+// score aligns every reached word against its target. Separators after
+// committed words count as typed and correct; the word being typed is
+// scored as a prefix so its untyped tail is not an error.
+func (s *Session) score() (typed, correct, correctWords, totalWords int, errs ErrorCounts) {
+	for i, input := range s.typed {
+		w := s.words[i]
+		word := s.target[w.start:w.end]
+		committed := i < s.current() || s.done
+		if !committed && len(input) == 0 {
+			break
+		}
+
+		matches, wordErrs := AlignWord(word, input, !committed)
+		typed += len(input)
+		correct += matches
+		errs.add(wordErrs)
+		for j, r := range s.lead[i] {
+			typed++
+			if r == s.indent(i)[j] {
+				correct++
+			} else {
+				errs.Substitutions++
+			}
+		}
+		if committed && i < len(s.words)-1 {
+			typed++
+			if s.ends[i] == s.lineBreak(i) {
+				correct++
+			} else {
+				errs.Substitutions++
+			}
+		}
+
+		totalWords++
+		if committed && string(input) == string(word) {
+			correctWords++
+		}
+	}
+	return typed, correct, correctWords, totalWords, errs
+}
+
 func (s *Session) Snapshot(now time.Time, timedOut, cancelled bool) Metrics {
 	elapsed := s.Elapsed(now)
-	correctWords, totalWords := CountCorrectWords(s.target, s.input)
+	typed, correct, correctWords, totalWords, errs := s.score()
+	samples := Samples(s.events, elapsed, s.interval)
+	completed := s.IsCompleted()
+	if s.kind == KindTime {
+		completed = timedOut
+	}
 	return Metrics{
-		WPM:          CalculateNetWPM(s.correctTyped, elapsed),
-		RawWPM:       CalculateRawWPM(s.totalTyped, elapsed),
-		Accuracy:     CalculateAccuracy(s.correctTyped, s.totalTyped),
-		Errors:       s.errors,
-		TotalTyped:   s.totalTyped,
-		Correct:      s.correctTyped,
+		Kind:         s.kind,
+		TimeLimit:    s.timeLimit,
+		WPM:          CalculateNetWPM(correct, elapsed),
+		RawWPM:       CalculateRawWPM(typed, elapsed),
+		Accuracy:     CalculateAccuracy(correct, correct+errs.Total()),
+		Errors:       errs.Total(),
+		TotalTyped:   typed,
+		Correct:      correct,
 		CorrectWords: correctWords,
 		TotalWords:   totalWords,
 		TimeTaken:    elapsed,
-		Completed:    s.IsCompleted(),
+		Completed:    completed,
 		TimedOut:     timedOut,
 		Cancelled:    cancelled,
+
+		Keystrokes:        s.keystrokes,
+		RawAccuracy:       CalculateAccuracy(s.keysCorrect, s.keystrokes),
+		CorrectedErrors:   s.correctedErrors,
+		UncorrectedErrors: errs.Total(),
+		Backspaces:        s.backspaces,
+		ErrorCounts:       errs,
+
+		Samples:     samples,
+		Consistency: Consistency(samples),
+		BurstWPM:    BurstWPM(samples),
 	}
 }
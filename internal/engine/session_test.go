@@ -16,7 +16,7 @@ func TestSessionTypingAndBackspace(t *testing.T) {
 	}
 
 	// Backspace should undo the 'x' error from counts
-	s.Backspace()
+	s.Backspace(start.Add(150 * time.Millisecond))
 	if s.Cursor() != 1 {
 		t.Fatalf("expected cursor 1 after backspace, got %d", s.Cursor())
 	}
@@ -55,3 +55,251 @@ func TestSessionTimeoutStartsOnFirstKey(t *testing.T) {
 		t.Fatal("expected timeout after first key and limit")
 	}
 }
+
+func TestTimedSessionGrowsTarget(t *testing.T) {
+	now := time.Now()
+	calls := 0
+	s := NewTimedSession(func() string {
+		calls++
+		return "ab cd"
+	}, 10*time.Second)
+
+	initial := len(s.Target())
+	if initial < lookahead {
+		t.Fatalf("expected at least %d runes buffered, got %d", lookahead, initial)
+	}
+	for i := 0; i < initial; i++ {
+		s.ApplyRune(s.Target()[i], now)
+	}
+	if len(s.Target())-s.Cursor() < lookahead {
+		t.Fatalf("expected target to keep %d runes ahead, got %d", lookahead, len(s.Target())-s.Cursor())
+	}
+	if s.IsCompleted() {
+		t.Fatal("timed session should never complete by text")
+	}
+}
+
+func TestTimedSessionStopsAtExhaustedSource(t *testing.T) {
+	now := time.Now()
+	chunks := []string{"ab cd"}
+	s := NewTimedSession(func() string {
+		if len(chunks) == 0 {
+			return ""
+		}
+		chunk := chunks[0]
+		chunks = chunks[1:]
+		return chunk
+	}, 10*time.Second)
+
+	for _, r := range "ab cd" {
+		s.ApplyRune(r, now)
+	}
+	if s.ApplyRune(' ', now) {
+		t.Fatal("a space after the last word should be ignored")
+	}
+	if len(s.ends) != len(s.typed)-1 || len(s.typed) != len(s.words) {
+		t.Fatalf("words out of step: %d typed, %d ended, %d words", len(s.typed), len(s.ends), len(s.words))
+	}
+	if m := s.Snapshot(now, false, false); m.CorrectWords != 1 || m.Errors != 0 {
+		t.Fatalf("expected one finished word and no errors, got %+v", m)
+	}
+}
+
+func TestTimedSessionCountsOnlyWindow(t *testing.T) {
+	now := time.Now()
+	s := NewTimedSession(func() string { return "aaaa" }, time.Second)
+
+	s.ApplyRune('a', now)
+	s.ApplyRune('a', now.Add(500*time.Millisecond))
+	if s.ApplyRune('a', now.Add(2*time.Second)) {
+		t.Fatal("keystroke after the time limit should be ignored")
+	}
+
+	m := s.Snapshot(now.Add(5*time.Second), true, false)
+	if m.TotalTyped != 2 {
+		t.Fatalf("expected 2 typed in window, got %d", m.TotalTyped)
+	}
+	if m.TimeTaken != time.Second {
+		t.Fatalf("expected elapsed capped at 1s, got %s", m.TimeTaken)
+	}
+	if m.Kind != KindTime || !m.Completed {
+		t.Fatalf("expected completed time test, got kind %q completed %v", m.Kind, m.Completed)
+	}
+}
+
+func TestSessionKeepsCorrectedErrors(t *testing.T) {
+	start := time.Now()
+	s := NewSession("abcd", 0)
+
+	s.ApplyRune('a', start)
+	s.ApplyRune('x', start) // wrong, corrected below
+	s.Backspace(start)
+	s.ApplyRune('b', start)
+	s.ApplyRune('y', start) // wrong, left in place
+	s.ApplyRune('d', start)
+
+	m := s.Snapshot(start.Add(time.Second), false, false)
+	if m.Keystrokes != 5 {
+		t.Fatalf("expected 5 keystrokes, got %d", m.Keystrokes)
+	}
+	if m.CorrectedErrors != 1 || m.UncorrectedErrors != 1 || m.Backspaces != 1 {
+		t.Fatalf("expected 1 corrected, 1 uncorrected, 1 backspace, got %d/%d/%d",
+			m.CorrectedErrors, m.UncorrectedErrors, m.Backspaces)
+	}
+	if m.Accuracy != 75 {
+		t.Fatalf("expected final-text accuracy 75, got %.1f", m.Accuracy)
+	}
+	if m.RawAccuracy != 60 {
+		t.Fatalf("expected raw accuracy 60, got %.1f", m.RawAccuracy)
+	}
+}
+
+func TestSessionResyncsAfterMissedLetter(t *testing.T) {
+	now := time.Now()
+	s := NewSession("hello world", 0)
+	for _, r := range "helo world" {
+		s.ApplyRune(r, now)
+	}
+	if !s.IsCompleted() {
+		t.Fatal("expected session to be completed")
+	}
+
+	m := s.Snapshot(now.Add(time.Second), false, false)
+	if m.Omissions != 1 || m.Errors != 1 {
+		t.Fatalf("expected a single omission, got %+v (errors %d)", m.ErrorCounts, m.Errors)
+	}
+	if m.CorrectWords != 1 || m.TotalWords != 2 {
+		t.Fatalf("expected world to still be correct, got %d/%d", m.CorrectWords, m.TotalWords)
+	}
+	// h e l o + space + w o r l d
+	if m.Correct != 10 {
+		t.Fatalf("expected 10 correct characters, got %d", m.Correct)
+	}
+}
+
+func TestSessionOverflowAndBackspaceAcrossWords(t *testing.T) {
+	now := time.Now()
+	s := NewSession("ab cd", 0)
+	for _, r := range "abx " {
+		s.ApplyRune(r, now)
+	}
+	words := s.Words()
+	if string(words[0].Typed) != "abx" || !words[0].Committed {
+		t.Fatalf("expected overflowing first word, got %+v", words[0])
+	}
+	if s.CurrentWord() != 1 {
+		t.Fatalf("expected to be on the second word, got %d", s.CurrentWord())
+	}
+
+	// Back over the space and the extra letter, then finish correctly.
+	s.Backspace(now)
+	s.Backspace(now)
+	if s.CurrentWord() != 0 || string(s.Words()[0].Typed) != "ab" {
+		t.Fatalf("expected to be back in the first word, got %+v", s.Words()[0])
+	}
+	for _, r := range " cd" {
+		s.ApplyRune(r, now)
+	}
+	m := s.Snapshot(now.Add(time.Second), false, false)
+	if m.Errors != 0 || m.CorrectedErrors != 1 || m.CorrectWords != 2 {
+		t.Fatalf("expected clean result with one corrected error, got %+v", m)
+	}
+}
+
+func TestSessionIgnoresLeadingSpace(t *testing.T) {
+	s := NewSession("ab", 0)
+	if s.ApplyRune(' ', time.Now()) || s.Started() {
+		t.Fatal("space at the start of a word should be ignored")
+	}
+}
+
+func TestSessionMissedWords(t *testing.T) {
+	now := time.Now()
+	s := NewSession("one two three four", 0)
+	for _, r := range "one tow three fo" {
+		s.ApplyRune(r, now)
+	}
+	missed := s.MissedWords()
+	if len(missed) != 1 || missed[0].Expected != "two" || missed[0].Typed != "tow" {
+		t.Fatalf("expected only two/tow missed, got %+v", missed)
+	}
+}
+
+func TestSessionTypesLinesAndIndentation(t *testing.T) {
+	now := time.Now()
+	s := NewSession("if x {\n\treturn\n}", 0)
+	for _, r := range "if x {\n\treturn\n}" {
+		s.ApplyRune(r, now)
+	}
+	if !s.IsCompleted() {
+		t.Fatalf("expected the session to complete, cursor at %d", s.Cursor())
+	}
+	m := s.Snapshot(now.Add(time.Second), false, false)
+	if m.Errors != 0 || m.Correct != len(s.Target()) || m.TotalTyped != len(s.Target()) {
+		t.Fatalf("expected every rune typed correctly, got %+v", m)
+	}
+}
+
+func TestSessionTabTypesIndentUnit(t *testing.T) {
+	now := time.Now()
+	s := NewSession("def f():\n    if x:\n        pass", 0)
+	if s.IndentUnit() != 4 {
+		t.Fatalf("expected an indent unit of 4, got %d", s.IndentUnit())
+	}
+	for _, r := range "def f():\n\tif x:\n\t\tpass" {
+		s.ApplyRune(r, now)
+	}
+	m := s.Snapshot(now.Add(time.Second), false, false)
+	if !s.IsCompleted() || m.Errors != 0 || m.TotalTyped != len(s.Target()) {
+		t.Fatalf("expected tabs to type the indentation, got %+v", m)
+	}
+}
+
+func TestSessionWrongLineBreak(t *testing.T) {
+	now := time.Now()
+	s := NewSession("a b\nc", 0)
+	if !s.ApplyRune('a', now) || s.ApplyRune('\n', now) {
+		t.Fatal("Enter where a space is expected should be wrong")
+	}
+	s.ApplyRune('b', now)
+	if s.ApplyRune(' ', now) {
+		t.Fatal("space where a line break is expected should be wrong")
+	}
+	s.ApplyRune('c', now)
+	if m := s.Snapshot(now.Add(time.Second), false, false); !s.IsCompleted() || m.Errors != 2 || m.Substitutions != 2 {
+		t.Fatalf("expected two wrong breaks, got %+v", m)
+	}
+
+	// Backspace erases the wrong break and Enter retypes it.
+	s = NewSession("a\nb", 0)
+	s.ApplyRune('a', now)
+	s.ApplyRune(' ', now)
+	s.Backspace(now)
+	s.ApplyRune('\n', now)
+	s.ApplyRune('b', now)
+	if m := s.Snapshot(now.Add(time.Second), false, false); m.Errors != 0 || m.CorrectedErrors != 1 {
+		t.Fatalf("expected the corrected break, got %+v", m)
+	}
+}
+
+func TestSessionAutoIndent(t *testing.T) {
+	now := time.Now()
+	s := NewSession("{\n\t\tx\n}", 0)
+	s.SetAutoIndent(true)
+	s.ApplyRune('{', now)
+	s.ApplyRune('\n', now)
+	if s.Cursor() != 4 {
+		t.Fatalf("expected the cursor past the indentation, got %d", s.Cursor())
+	}
+	if s.ApplyRune('\t', now) {
+		t.Fatal("Tab should not match a letter after auto-indent")
+	}
+	s.Backspace(now)
+	for _, r := range "x\n}" {
+		s.ApplyRune(r, now)
+	}
+	m := s.Snapshot(now.Add(time.Second), false, false)
+	if !s.IsCompleted() || m.Errors != 0 || m.TotalTyped != len(s.Target())-2 {
+		t.Fatalf("expected skipped indentation not to count as typed, got %+v", m)
+	}
+}
@@ -1,99 +1,521 @@
 package history
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
+
+	"terminal-wpm/internal/config"
 )
 
 // Record stores the result of a single typing test.
 type Record struct {
-	Date         time.Time `json:"date"`
-	Mode         string    `json:"mode"`
-	WordCount    int       `json:"word_count"`
-	WPM          float64   `json:"wpm"`
-	RawWPM       float64   `json:"raw_wpm"`
-	Accuracy     float64   `json:"accuracy"`
-	Errors       int       `json:"errors"`
-	TimeTaken    float64   `json:"time_taken_sec"`
-	Completed    bool      `json:"completed"`
-	Tier         string    `json:"tier"`
+	Version     int       `json:"v"`            // schema version, see CurrentVersion
+	ID          string    `json:"id,omitempty"` // stable across machines, see NewID
+	Date        time.Time `json:"date"`
+	Mode        string    `json:"mode"`
+	TestType    string    `json:"test_type,omitempty"` // "words" or "time"
+	WordCount   int       `json:"word_count"`
+	QuoteLength string    `json:"quote_length,omitempty"` // length bucket of a quote test, e.g. "short"
+	TimeLimit   float64   `json:"time_limit_sec,omitempty"`
+	Language    string    `json:"language,omitempty"`
+	Modifiers   []string  `json:"modifiers,omitempty"` // text options such as punctuation or numbers
+	WPM         float64   `json:"wpm"`
+	RawWPM      float64   `json:"raw_wpm"`
+	Accuracy    float64   `json:"accuracy"`
+	Errors      int       `json:"errors"`
+
+	RawAccuracy       float64 `json:"raw_accuracy,omitempty"`
+	CorrectedErrors   int     `json:"corrected_errors,omitempty"`
+	UncorrectedErrors int     `json:"uncorrected_errors,omitempty"`
+	Backspaces        int     `json:"backspaces,omitempty"`
+	Consistency       float64 `json:"consistency,omitempty"`
+	BurstWPM          float64 `json:"burst_wpm,omitempty"`
+
+	TimeTaken float64  `json:"time_taken_sec"`
+	Completed bool     `json:"completed"`
+	Tier      string   `json:"tier"`
+	Samples   []Sample `json:"samples,omitempty"` // speed over time, for charting the result again
+}
+
+// Sample is one interval of a test's speed series, see engine.Sample.
+type Sample struct {
+	At     float64 `json:"t_sec"`   // end of the interval, since the first keystroke
+	WPM    float64 `json:"wpm"`     // net WPM from the start of the test
+	RawWPM float64 `json:"raw_wpm"` // raw WPM inside the interval
+	Errors int     `json:"errors,omitempty"`
 }
 
-const maxRecords = 50
+// Label describes the test length, e.g. "60 words", "short quote" or "60s".
+func (r Record) Label() string {
+	switch {
+	case r.TestType == "time":
+		return fmt.Sprintf("%gs", r.TimeLimit)
+	case r.QuoteLength != "":
+		return r.QuoteLength + " quote"
+	}
+	return fmt.Sprintf("%d words", r.WordCount)
+}
+
+// Tier names the speed band a WPM falls in.
+func Tier(wpm float64) string {
+	switch {
+	case wpm < 30:
+		return "Beginner"
+	case wpm < 50:
+		return "Average"
+	case wpm < 80:
+		return "Fast"
+	default:
+		return "Elite"
+	}
+}
 
-// historyPath returns the path to the JSON file inside the user's config dir.
-func historyPath() (string, error) {
-	cfgDir, err := os.UserConfigDir()
+const (
+	fileName       = "history.jsonl" // one JSON record per line, oldest first
+	lockName       = "history.lock"
+	legacyName     = "history.json"             // the old single-array format
+	quarantineName = "history.jsonl.quarantine" // lines that could not be read
+	corruptExt     = ".corrupt"                 // an old file that is not an array at all
+)
+
+// Store is the history file in one directory. Writers append a line under
+// an exclusive advisory lock, so several typr instances can save at once.
+// A syncing store writes its records to a shard of a shared directory
+// instead and reads the shards of every machine, see Sync.
+type Store struct {
+	dir       string
+	retention int
+	syncDir   string
+	machine   string
+}
+
+// New returns the store in dir that keeps the last retention records, or
+// all of them when retention is 0.
+func New(dir string, retention int) *Store {
+	return &Store{dir: dir, retention: retention}
+}
+
+// Open returns the store in the user's config directory, syncing through
+// settings.SyncDir when it is set.
+func Open(settings config.Settings) (*Store, error) {
+	dir, err := config.ProfileDir(settings.Profile)
+	if err != nil {
+		return nil, err
+	}
+	s := New(dir, settings.HistoryRetention)
+	if settings.SyncDir == "" {
+		return s, nil
+	}
+	// The machine name is shared by all profiles; each profile has its own
+	// set of shards.
+	base, err := config.Dir()
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	machine, err := machineName(base)
+	if err != nil {
+		return nil, err
 	}
-	dir := filepath.Join(cfgDir, "terminal-wpm")
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return "", err
+	syncDir := settings.SyncDir
+	if settings.Profile != "" {
+		syncDir = filepath.Join(syncDir, "profiles", settings.Profile)
 	}
-	return filepath.Join(dir, "history.json"), nil
+	return s.Sync(syncDir, machine), nil
 }
 
-// Load reads all saved records from disk.
-func Load() ([]Record, error) {
-	path, err := historyPath()
+// Path returns the file this store writes to: the local history, or this
+// machine's shard when syncing.
+func (s *Store) Path() string {
+	if s.syncDir != "" {
+		return filepath.Join(s.syncDir, s.machine+shardExt)
+	}
+	return filepath.Join(s.dir, fileName)
+}
+
+// CorruptError reports lines that could not be read. Load returns it
+// together with every record that could; the next Save moves the lines
+// to the quarantine file next to the history. An old history.json that
+// cannot be read at all has no Lines; MovedTo names the file Save sets
+// it aside as, and Save returns the error again once it has.
+type CorruptError struct {
+	Path    string
+	Lines   []int
+	MovedTo string
+
+	moved bool
+}
+
+func (e *CorruptError) Error() string {
+	switch {
+	case e.moved:
+		return fmt.Sprintf("%s could not be read and was moved to %s", e.Path, e.MovedTo)
+	case e.MovedTo != "":
+		return fmt.Sprintf("%s could not be read; it will be moved to %s on the next save", e.Path, e.MovedTo)
+	}
+	return fmt.Sprintf("%s: skipped %d unreadable line(s) %v; they will be moved to %s on the next save",
+		e.Path, len(e.Lines), e.Lines, quarantineName)
+}
+
+// Load reads all saved records, oldest first. When some lines cannot be
+// read it returns the rest along with a *CorruptError.
+func (s *Store) Load() ([]Record, error) {
+	unlock, err := s.lock(false)
 	if err != nil {
 		return nil, err
 	}
+	defer unlock()
+
+	sc, err := s.scanAll()
+	if err != nil {
+		return nil, err
+	}
+	return sc.records, sc.corrupt()
+}
+
+// Save appends a record and updates the personal bests. It first migrates
+// an old history.json, moves unreadable lines to the quarantine file, and
+// drops the oldest records beyond the retention limit.
+func (s *Store) Save(r Record) error {
+	unlock, err := s.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	warning, err := s.migrate()
+	if err != nil {
+		return err
+	}
+	sc, err := s.scan()
+	if err != nil {
+		return err
+	}
+	r.Version = CurrentVersion
+	if r.ID == "" {
+		r.ID = NewID()
+	}
+	records := append(sc.records, r)
+	rewrite := len(sc.bad) > 0 || sc.unterminated // appending would join two lines
+	if s.retention > 0 && len(records) > s.retention {
+		records = records[len(records)-s.retention:]
+		rewrite = true
+	}
+	if rewrite {
+		if err := s.quarantine(sc.bad); err != nil {
+			return err
+		}
+		if err := s.rewrite(records, sc.newer); err != nil {
+			return err
+		}
+	} else if err := s.append(r); err != nil {
+		return err
+	}
+	if err := s.updateBests(r); err != nil {
+		return err
+	}
+	return warning
+}
+
+// Recent returns the last n readable records (most recent last).
+func (s *Store) Recent(n int) []Record {
+	records, _ := s.Load()
+	if len(records) == 0 {
+		return nil
+	}
+	if n > len(records) {
+		n = len(records)
+	}
+	return records[len(records)-n:]
+}
+
+// lock takes the store's advisory lock and returns its release function.
+func (s *Store) lock(exclusive bool) (func(), error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(s.dir, lockName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, exclusive); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("lock history: %w", err)
+	}
+	return func() {
+		_ = unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// scanned is the parsed content of a history file.
+type scanned struct {
+	path    string
+	records []Record
+	newer   [][]byte  // lines from a newer schema, kept as they are
+	bad     []badLine // lines that could not be read
 
+	unterminated bool          // the last line has no newline
+	unreadable   *CorruptError // an old history.json that is not an array
+}
+
+// badLine is an unreadable line, the file it came from and why.
+type badLine struct {
+	n    int
+	from string
+	raw  []byte
+	err  error
+}
+
+func (sc scanned) empty() bool {
+	return len(sc.records) == 0 && len(sc.newer) == 0 && len(sc.bad) == 0
+}
+
+func (sc scanned) corrupt() error {
+	var errs []error
+	if len(sc.bad) > 0 {
+		e := &CorruptError{Path: sc.path}
+		for _, b := range sc.bad {
+			e.Lines = append(e.Lines, b.n)
+		}
+		errs = append(errs, e)
+	}
+	if sc.unreadable != nil {
+		errs = append(errs, sc.unreadable)
+	}
+	return errors.Join(errs...)
+}
+
+// add decodes one line into the matching bucket.
+func (sc *scanned) add(n int, line []byte) {
+	r, err := decodeRecord(line)
+	switch {
+	case err == nil:
+		sc.records = append(sc.records, r)
+	case errors.Is(err, errNewerVersion):
+		sc.newer = append(sc.newer, line)
+	default:
+		sc.bad = append(sc.bad, badLine{n: n, from: filepath.Base(sc.path), raw: line, err: err})
+	}
+}
+
+// scan reads the JSON Lines file. A final line without a newline that
+// does not parse is a write that was interrupted.
+func (s *Store) scan() (scanned, error) {
+	return scanFile(s.Path())
+}
+
+// scanFile reads one JSON Lines file, see scan.
+func scanFile(path string) (scanned, error) {
+	sc := scanned{path: path}
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil, nil // no history yet
+			return sc, nil // no history yet
 		}
-		return nil, err
+		return sc, err
 	}
 
-	var records []Record
-	if err := json.Unmarshal(data, &records); err != nil {
-		return nil, err
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		bad := len(sc.bad)
+		sc.add(i+1, line)
+		if i == len(lines)-1 {
+			sc.unterminated = true
+			if len(sc.bad) > bad {
+				sc.bad[bad].err = fmt.Errorf("incomplete line: %w", sc.bad[bad].err)
+			}
+		}
 	}
-	return records, nil
+	return sc, nil
 }
 
-// Save appends a record and persists to disk, keeping only the last maxRecords.
-func Save(r Record) error {
-	records, err := Load()
+// scanLegacy reads the old history.json array, one entry per "line". A
+// missing file is not an error; one that is not an array is a
+// *CorruptError.
+func scanLegacy(path string) (scanned, error) {
+	sc := scanned{path: path}
+	data, err := os.ReadFile(path)
 	if err != nil {
-		// If history is corrupted, start fresh rather than blocking the user.
-		records = nil
+		if errors.Is(err, os.ErrNotExist) {
+			return sc, nil
+		}
+		return sc, err
+	}
+	if sc, err = scanArray(path, data); err != nil {
+		return sc, &CorruptError{Path: path, MovedTo: path + corruptExt}
+	}
+	return sc, nil
+}
+
+// scanArray reads a JSON array of records, one entry per "line".
+func scanArray(path string, data []byte) (scanned, error) {
+	sc := scanned{path: path}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return sc, fmt.Errorf("%s: %w", path, err)
+	}
+	for i, e := range entries {
+		sc.add(i+1, e)
 	}
+	return sc, nil
+}
 
-	records = append(records, r)
+// append writes one record as a single line.
+func (s *Store) append(r Record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.Path(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
 
-	// Trim to last N records.
-	if len(records) > maxRecords {
-		records = records[len(records)-maxRecords:]
+// rewrite atomically replaces the history file with records followed by
+// lines from newer versions.
+func (s *Store) rewrite(records []Record, newer [][]byte) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range records {
+		r.Version = CurrentVersion
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	for _, line := range newer {
+		buf.Write(line)
+		buf.WriteByte('\n')
 	}
+	return writeAtomic(s.Path(), buf.Bytes())
+}
+
+// quarantined is one line of the quarantine file.
+type quarantined struct {
+	At    time.Time `json:"quarantined_at"`
+	From  string    `json:"from"`
+	Line  int       `json:"line"`
+	Error string    `json:"error"`
+	Raw   string    `json:"raw"`
+}
 
-	path, err := historyPath()
+// quarantine appends unreadable lines to the quarantine file, with the
+// reason, so they can be inspected and repaired by hand.
+func (s *Store) quarantine(bad []badLine) error {
+	if len(bad) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(filepath.Join(s.dir, quarantineName), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	now := time.Now()
+	for _, b := range bad {
+		q := quarantined{At: now, From: b.from, Line: b.n, Error: b.err.Error(), Raw: string(b.raw)}
+		if err := enc.Encode(q); err != nil {
+			return err
+		}
+	}
+	return f.Sync()
+}
 
-	data, err := json.MarshalIndent(records, "", "  ")
+// migrate converts history.json into the JSON Lines file once, keeping the
+// old file as history.json.bak. Entries that cannot be read are
+// quarantined; a file that is not a JSON array at all is set aside as
+// history.json.corrupt and returned as the warning. A syncing store then
+// moves the local history.jsonl into its shard the same way, keeping
+// history.jsonl.synced.
+func (s *Store) migrate() (warning error, err error) {
+	legacy := filepath.Join(s.dir, legacyName)
+	if _, err := os.Stat(legacy); err == nil {
+		old, err := scanLegacy(legacy)
+		var corrupt *CorruptError
+		switch {
+		case errors.As(err, &corrupt):
+			if err := os.Rename(legacy, corrupt.MovedTo); err != nil {
+				return nil, err
+			}
+			corrupt.moved = true
+			warning = corrupt
+		case err != nil:
+			return nil, err
+		default:
+			for i := range old.bad {
+				old.bad[i].err = fmt.Errorf("%s entry %d: %w", legacyName, old.bad[i].n, old.bad[i].err)
+			}
+			if err := s.absorb(old); err != nil {
+				return nil, err
+			}
+			if err := os.Rename(legacy, legacy+".bak"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	local := filepath.Join(s.dir, fileName)
+	if s.syncDir == "" {
+		return warning, nil
+	}
+	if _, err := os.Stat(local); err != nil {
+		return warning, nil // nothing to move into the shard
+	}
+	old, err := scanFile(local)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.absorb(old); err != nil {
+		return nil, err
+	}
+	return warning, os.Rename(local, local+".synced")
+}
+
+// absorb puts the records of an older file in front of the store's own and
+// quarantines the unreadable lines of both.
+func (s *Store) absorb(old scanned) error {
+	current, err := s.scan()
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0o644)
+	if err := s.quarantine(append(old.bad, current.bad...)); err != nil {
+		return err
+	}
+	return s.rewrite(append(old.records, current.records...), append(old.newer, current.newer...))
 }
 
-// Recent returns the last n records (most recent last).
-func Recent(n int) []Record {
-	records, err := Load()
-	if err != nil || len(records) == 0 {
-		return nil
+// writeAtomic writes data to a temporary file next to path and renames it
+// into place, so readers see either the old or the new file.
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
 	}
-	if n > len(records) {
-		n = len(records)
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
 	}
-	return records[len(records)-n:]
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
@@ -0,0 +1,214 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSaveAppendsLines(t *testing.T) {
+	s := New(t.TempDir(), 0)
+	for i := range 3 {
+		if err := s.Save(Record{Mode: "quote", WordCount: 30, WPM: float64(i)}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+	records, err := s.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(records) != 3 || records[2].WPM != 2 {
+		t.Fatalf("expected 3 records in order, got %+v", records)
+	}
+}
+
+func TestSaveKeepsRetention(t *testing.T) {
+	s := New(t.TempDir(), 2)
+	for i := range 5 {
+		if err := s.Save(Record{WPM: float64(i)}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+	records, _ := s.Load()
+	if len(records) != 2 || records[0].WPM != 3 || records[1].WPM != 4 {
+		t.Fatalf("expected the last 2 records, got %+v", records)
+	}
+}
+
+func TestSaveMigratesLegacyFile(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, legacyName)
+	if err := os.WriteFile(legacy, []byte(`[{"mode":"quote","wpm":40},{"mode":"code","wpm":50}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := New(dir, 0)
+
+	records, err := s.Load()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("expected legacy records before migration, got %v, %v", records, err)
+	}
+	if err := s.Save(Record{Mode: "quote", WPM: 60}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	records, _ = s.Load()
+	if len(records) != 3 || records[0].WPM != 40 || records[2].WPM != 60 {
+		t.Fatalf("expected legacy records followed by the new one, got %+v", records)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Fatalf("expected history.json to be moved aside, got %v", err)
+	}
+	if _, err := os.Stat(legacy + ".bak"); err != nil {
+		t.Fatalf("expected a backup of history.json: %v", err)
+	}
+}
+
+func TestUnreadableLegacyFileIsReported(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, legacyName)
+	if err := os.WriteFile(legacy, []byte(`{"mode":"quote"`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := New(dir, 0)
+
+	_, err := s.Load()
+	var corrupt *CorruptError
+	if !errors.As(err, &corrupt) || corrupt.MovedTo != legacy+".corrupt" {
+		t.Fatalf("expected a CorruptError naming history.json.corrupt, got %v", err)
+	}
+	err = s.Save(Record{Mode: "quote", WPM: 60})
+	if !errors.As(err, &corrupt) || !strings.Contains(err.Error(), "was moved to "+legacy+".corrupt") {
+		t.Fatalf("expected save to report the moved file, got %v", err)
+	}
+	if _, err := os.Stat(legacy + ".corrupt"); err != nil {
+		t.Fatalf("expected history.json to be set aside: %v", err)
+	}
+	records, err := s.Load()
+	if err != nil || len(records) != 1 || records[0].WPM != 60 {
+		t.Fatalf("expected the new record to be saved, got %+v, %v", records, err)
+	}
+}
+
+func TestLastLineWithoutNewlineIsKept(t *testing.T) {
+	s := New(t.TempDir(), 0)
+	if err := os.WriteFile(s.Path(), []byte("{\"wpm\":40}\n{\"wpm\":50}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	records, err := s.Load()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("expected both records, got %v, %v", records, err)
+	}
+	if err := s.Save(Record{WPM: 60}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	records, err = s.Load()
+	if err != nil || len(records) != 3 || records[1].WPM != 50 || records[2].WPM != 60 {
+		t.Fatalf("expected the unterminated record kept before the new one, got %+v, %v", records, err)
+	}
+	if _, err := os.Stat(filepath.Join(s.dir, quarantineName)); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing quarantined, got %v", err)
+	}
+}
+
+func TestUnreadableLinesAreQuarantined(t *testing.T) {
+	s := New(t.TempDir(), 0)
+	data := "{\"wpm\":40}\nnot json\n{\"wpm\":\"fast\"}\n{\"wpm\":5"
+	if err := os.WriteFile(s.Path(), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := s.Load()
+	var corrupt *CorruptError
+	if !errors.As(err, &corrupt) || len(corrupt.Lines) != 3 {
+		t.Fatalf("expected 3 unreadable lines reported, got %v", err)
+	}
+	if len(records) != 1 || records[0].WPM != 40 {
+		t.Fatalf("expected the readable record, got %+v", records)
+	}
+
+	if err := s.Save(Record{WPM: 60}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	records, err = s.Load()
+	if err != nil || len(records) != 2 || records[1].WPM != 60 {
+		t.Fatalf("expected a clean file after save, got %v, %v", records, err)
+	}
+	q, err := os.ReadFile(filepath.Join(s.dir, quarantineName))
+	if err != nil {
+		t.Fatalf("expected a quarantine file: %v", err)
+	}
+	for _, raw := range []string{"not json", "fast", "wpm\\\":5"} {
+		if !strings.Contains(string(q), raw) {
+			t.Fatalf("expected quarantine to keep %q, got:\n%s", raw, q)
+		}
+	}
+}
+
+func TestLoadMigratesOldVersions(t *testing.T) {
+	s := New(t.TempDir(), 0)
+	data := "{\"mode\":\"quote\",\"word_count\":30}\n{\"v\":2,\"test_type\":\"time\",\"time_limit_sec\":60}\n" +
+		"{\"v\":5,\"mode\":\"words\",\"test_type\":\"words\",\"word_count\":30}\n"
+	if err := os.WriteFile(s.Path(), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	records, err := s.Load()
+	if err != nil || len(records) != 3 {
+		t.Fatalf("load: %v, %v", records, err)
+	}
+	if records[0].Version != CurrentVersion || records[0].TestType != "words" {
+		t.Fatalf("expected an unversioned record upgraded to a word test, got %+v", records[0])
+	}
+	if records[1].TestType != "time" {
+		t.Fatalf("expected the current record unchanged, got %+v", records[1])
+	}
+	if records[2].Version != CurrentVersion || records[2].Samples != nil {
+		t.Fatalf("expected a v5 record upgraded without a speed series, got %+v", records[2])
+	}
+}
+
+func TestNewerVersionsAreKept(t *testing.T) {
+	s := New(t.TempDir(), 1)
+	future := `{"v":99,"wpm":70,"shiny":true}`
+	if err := os.WriteFile(s.Path(), []byte(future+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := s.Save(Record{WPM: 60}); err != nil { // retention 1 forces a rewrite
+			t.Fatalf("save: %v", err)
+		}
+	}
+	records, err := s.Load()
+	if err != nil || len(records) != 1 {
+		t.Fatalf("expected only the current record loaded, got %v, %v", records, err)
+	}
+	data, _ := os.ReadFile(s.Path())
+	if !strings.Contains(string(data), future) {
+		t.Fatalf("expected the newer record kept verbatim, got:\n%s", data)
+	}
+}
+
+func TestConcurrentSavesKeepEveryRecord(t *testing.T) {
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Separate stores behave like separate processes.
+			if err := New(dir, 0).Save(Record{WPM: float64(i)}); err != nil {
+				t.Errorf("save: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	records, err := New(dir, 0).Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(records) != 20 {
+		t.Fatalf("expected 20 records, got %d", len(records))
+	}
+}
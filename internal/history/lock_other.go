@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package history
+
+import "os"
+
+// Platforms without file locking fall back to unlocked access.
+func lockFile(*os.File, bool) error { return nil }
+
+func unlockFile(*os.File) error { return nil }
@@ -0,0 +1,74 @@
+package history
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MonkeytypeMode is the mode given to results imported from monkeytype.
+// Its word lists differ from typr's, so they get their own personal bests.
+const MonkeytypeMode = "monkeytype"
+
+// isMonkeytype reports whether a CSV header is monkeytype's results
+// export (_id, isPb, wpm, acc, rawWpm, consistency, charStats, mode,
+// mode2, ..., timestamp).
+func isMonkeytype(col map[string]int) bool {
+	for _, name := range []string{"_id", "charStats", "mode2", "timestamp"} {
+		if _, ok := col[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// monkeytypeRow converts one row of a monkeytype export. Time and word
+// tests keep their length; quote, zen and custom tests become word tests
+// of unknown length.
+func monkeytypeRow(get func(string) string) (Record, error) {
+	p := &fieldParser{get: get}
+	ms, err := strconv.ParseInt(get("timestamp"), 10, 64)
+	if err != nil {
+		return Record{}, fmt.Errorf("timestamp: %w", err)
+	}
+	r := Record{
+		ID:          "monkeytype-" + get("_id"),
+		Date:        time.UnixMilli(ms),
+		Mode:        MonkeytypeMode,
+		TestType:    "words",
+		Language:    get("language"),
+		WPM:         p.float("wpm"),
+		RawWPM:      p.float("rawWpm"),
+		Accuracy:    p.float("acc"),
+		Consistency: p.float("consistency"),
+		TimeTaken:   p.float("testDuration"),
+		Completed:   !p.bool("bailedOut"),
+	}
+	switch get("mode") {
+	case "time":
+		r.TestType = "time"
+		r.TimeLimit = p.float("mode2")
+	case "words":
+		r.WordCount = p.int("mode2")
+	}
+	for _, mod := range []string{"punctuation", "numbers"} {
+		if p.bool(mod) {
+			r.Modifiers = append(r.Modifiers, mod)
+		}
+	}
+
+	// charStats is "correct;incorrect;extra;missed".
+	if stats := strings.Split(get("charStats"), ";"); len(stats) == 4 {
+		for _, s := range stats[1:] {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return Record{}, fmt.Errorf("charStats: %w", err)
+			}
+			r.Errors += n
+		}
+		r.UncorrectedErrors = r.Errors
+	}
+	r.Tier = Tier(r.WPM)
+	return r, p.err
+}
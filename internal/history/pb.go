@@ -0,0 +1,242 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"terminal-wpm/internal/content"
+)
+
+const (
+	pbName = "pbs.json" // personal bests, next to the history
+
+	// PracticeMode marks drills of missed words; they never set a PB.
+	PracticeMode = "practice"
+)
+
+// Key identifies a test configuration. Personal bests are kept per key.
+type Key struct {
+	Mode        string  `json:"mode"`
+	TestType    string  `json:"test_type"`
+	WordCount   int     `json:"word_count,omitempty"`
+	QuoteLength string  `json:"quote_length,omitempty"`
+	TimeLimit   float64 `json:"time_limit_sec,omitempty"`
+	Language    string  `json:"language,omitempty"`
+	Modifiers   string  `json:"modifiers,omitempty"` // sorted and joined with "+"
+}
+
+// Key returns the configuration the record was typed in.
+func (r Record) Key() Key {
+	k := Key{Mode: r.Mode, TestType: r.TestType, Language: r.Language}
+	switch {
+	case r.TestType == "time":
+		k.TimeLimit = r.TimeLimit
+	case r.QuoteLength != "":
+		k.QuoteLength = r.QuoteLength // quotes in a bucket differ in length
+	default:
+		k.WordCount = r.WordCount
+	}
+	mods := slices.Clone(r.Modifiers)
+	slices.Sort(mods)
+	k.Modifiers = strings.Join(slices.Compact(mods), "+")
+	return k
+}
+
+// Label describes the test length, e.g. "60 words", "short quote" or "60s".
+func (k Key) Label() string {
+	return Record{TestType: k.TestType, WordCount: k.WordCount, QuoteLength: k.QuoteLength, TimeLimit: k.TimeLimit}.Label()
+}
+
+// String describes the configuration, e.g. "quote • 30 words • english".
+func (k Key) String() string {
+	parts := []string{k.Mode, k.Label()}
+	if k.Language != "" {
+		parts = append(parts, k.Language)
+	}
+	if k.Modifiers != "" {
+		parts = append(parts, k.Modifiers)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// untrackedModes never set a PB: practice drills, and the user's own text
+// or code, which differs from one file, list or tree to the next.
+var untrackedModes = map[string]bool{
+	PracticeMode:         true,
+	content.TextMode:     true,
+	content.WordListMode: true,
+	content.CodebaseMode: true,
+}
+
+// CountsForPB reports whether the record can be a personal best: the test
+// ran to the end and was not a practice drill or the user's own text or code.
+func (r Record) CountsForPB() bool {
+	return r.Completed && !untrackedModes[r.Mode]
+}
+
+// pbEntry is one line of pbs.json.
+type pbEntry struct {
+	Key    Key    `json:"key"`
+	Record Record `json:"record"`
+}
+
+// PersonalBests returns the fastest record for every configuration.
+func (s *Store) PersonalBests() (map[Key]Record, error) {
+	unlock, err := s.lock(false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	bests, err := s.readBests()
+	if err != nil || s.syncDir == "" {
+		return bests, err
+	}
+	// pbs.json only follows this machine; fold in the others' records.
+	sc, err := s.scanAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range sc.records {
+		improveBest(bests, r)
+	}
+	return bests, nil
+}
+
+// PersonalBest returns the fastest record typed in configuration k.
+func (s *Store) PersonalBest(k Key) (Record, bool) {
+	bests, err := s.PersonalBests()
+	if err != nil {
+		return Record{}, false
+	}
+	r, ok := bests[k]
+	return r, ok
+}
+
+// readBests loads pbs.json, or works the bests out from the history when
+// the file does not exist yet. The caller holds the lock.
+func (s *Store) readBests() (map[Key]Record, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, pbName))
+	if errors.Is(err, os.ErrNotExist) {
+		return s.scanBests()
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []struct {
+		Key    Key             `json:"key"`
+		Record json.RawMessage `json:"record"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", pbName, err)
+	}
+	bests := make(map[Key]Record, len(entries))
+	for _, e := range entries {
+		// Older bests are migrated like the history, which may move them
+		// to another configuration. Bests from a newer typr keep their key.
+		r, err := decodeRecord(e.Record)
+		if err != nil {
+			if err := json.Unmarshal(e.Record, &r); err != nil {
+				return nil, fmt.Errorf("%s: %w", pbName, err)
+			}
+			bests[e.Key] = r
+			continue
+		}
+		if !r.CountsForPB() {
+			continue // set before the mode stopped counting
+		}
+		if best, ok := bests[r.Key()]; !ok || r.WPM > best.WPM {
+			bests[r.Key()] = r
+		}
+	}
+	return bests, nil
+}
+
+// scanBests works the bests out from the history, including files that
+// have not been migrated yet.
+func (s *Store) scanBests() (map[Key]Record, error) {
+	sc, err := s.scanAll()
+	if err != nil {
+		return nil, err
+	}
+	bests := make(map[Key]Record)
+	for _, r := range sc.records {
+		improveBest(bests, r)
+	}
+	return bests, nil
+}
+
+// improveBest records r if it beats the best for its configuration and
+// reports whether it did.
+func improveBest(bests map[Key]Record, r Record) bool {
+	if !r.CountsForPB() {
+		return false
+	}
+	k := r.Key()
+	if best, ok := bests[k]; ok && best.WPM >= r.WPM {
+		return false
+	}
+	bests[k] = r
+	return true
+}
+
+// updateBests folds r into pbs.json. The caller holds the exclusive lock.
+func (s *Store) updateBests(r Record) error {
+	_, statErr := os.Stat(filepath.Join(s.dir, pbName))
+	bests, err := s.readBests()
+	if err != nil {
+		return err
+	}
+	// A freshly scanned history already includes r.
+	if !improveBest(bests, r) && statErr == nil {
+		return nil
+	}
+	return s.writeBests(bests)
+}
+
+// writeBests atomically replaces pbs.json, ordered for stable diffs.
+func (s *Store) writeBests(bests map[Key]Record) error {
+	entries := make([]pbEntry, 0, len(bests))
+	for k, r := range bests {
+		r.Version = CurrentVersion
+		entries = append(entries, pbEntry{Key: k, Record: r})
+	}
+	slices.SortFunc(entries, func(a, b pbEntry) int { return CompareKeys(a.Key, b.Key) })
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeAtomic(filepath.Join(s.dir, pbName), append(data, '\n'))
+}
+
+// CompareKeys orders configurations by mode, test type, length, language
+// and modifiers.
+func CompareKeys(a, b Key) int {
+	if c := strings.Compare(a.Mode, b.Mode); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.TestType, b.TestType); c != 0 {
+		return c
+	}
+	if c := a.WordCount - b.WordCount; c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.QuoteLength, b.QuoteLength); c != 0 {
+		return c
+	}
+	if a.TimeLimit != b.TimeLimit {
+		if a.TimeLimit < b.TimeLimit {
+			return -1
+		}
+		return 1
+	}
+	if c := strings.Compare(a.Language, b.Language); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Modifiers, b.Modifiers)
+}
@@ -0,0 +1,90 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"terminal-wpm/internal/content"
+)
+
+func TestSaveTracksBestPerConfiguration(t *testing.T) {
+	s := New(t.TempDir(), 0)
+	words := Record{Mode: "quote", TestType: "words", WordCount: 30, Language: "english", Completed: true}
+	timed := Record{Mode: "quote", TestType: "time", TimeLimit: 60, Language: "english", Completed: true}
+	for _, r := range []Record{
+		with(words, 50), with(words, 70), with(words, 60),
+		with(timed, 40),
+		{Mode: "quote", TestType: "words", WordCount: 30, Language: "english", WPM: 99}, // cancelled
+		{Mode: PracticeMode, TestType: "words", WordCount: 30, Completed: true, WPM: 120},
+		{Mode: content.TextMode, TestType: "words", WordCount: 30, Completed: true, WPM: 110},
+		{Mode: content.WordListMode, TestType: "words", WordCount: 30, Completed: true, WPM: 130},
+		{Mode: content.CodebaseMode, TestType: "words", WordCount: 30, Completed: true, WPM: 140},
+	} {
+		if err := s.Save(r); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	bests, err := s.PersonalBests()
+	if err != nil {
+		t.Fatalf("bests: %v", err)
+	}
+	if len(bests) != 2 {
+		t.Fatalf("expected 2 configurations, got %+v", bests)
+	}
+	if got := bests[words.Key()].WPM; got != 70 {
+		t.Fatalf("expected 70 WPM for 30 words, got %.0f", got)
+	}
+	if got := bests[timed.Key()].WPM; got != 40 {
+		t.Fatalf("expected 40 WPM for 60s, got %.0f", got)
+	}
+}
+
+func TestModifiersSeparateBests(t *testing.T) {
+	a := Record{Mode: "quote", TestType: "words", WordCount: 30, Modifiers: []string{"numbers", "punctuation"}}
+	b := Record{Mode: "quote", TestType: "words", WordCount: 30, Modifiers: []string{"punctuation", "numbers"}}
+	c := Record{Mode: "quote", TestType: "words", WordCount: 30}
+	if a.Key() != b.Key() {
+		t.Fatalf("expected modifier order not to matter: %v vs %v", a.Key(), b.Key())
+	}
+	if a.Key() == c.Key() {
+		t.Fatalf("expected modifiers to change the key")
+	}
+}
+
+func TestBestsRebuiltFromHistory(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `[{"mode":"quote","word_count":30,"wpm":40,"completed":true},{"mode":"quote","word_count":30,"wpm":55,"completed":true}]`
+	if err := os.WriteFile(filepath.Join(dir, legacyName), []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := New(dir, 0)
+	// Quote mode used to give random words, so old records become words tests.
+	best, ok := s.PersonalBest(Key{Mode: "words", TestType: "words", WordCount: 30, Language: "english"})
+	if !ok || best.WPM != 55 {
+		t.Fatalf("expected the migrated best of 55 WPM, got %+v, %v", best, ok)
+	}
+}
+
+func with(r Record, wpm float64) Record {
+	r.WPM = wpm
+	return r
+}
+
+func TestBestsFileMigrated(t *testing.T) {
+	dir := t.TempDir()
+	old := `[{"key":{"mode":"quote","test_type":"words","word_count":30,"language":"english"},` +
+		`"record":{"v":4,"id":"a","mode":"quote","test_type":"words","word_count":30,"language":"english","wpm":70,"completed":true}}]`
+	if err := os.WriteFile(filepath.Join(dir, pbName), []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bests, err := New(dir, 0).PersonalBests()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	best, ok := bests[Key{Mode: "words", TestType: "words", WordCount: 30, Language: "english"}]
+	if len(bests) != 1 || !ok || best.WPM != 70 {
+		t.Fatalf("expected the best to move to words mode, got %+v", bests)
+	}
+}
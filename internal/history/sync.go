@@ -0,0 +1,149 @@
+package history
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	shardExt    = ".jsonl"     // a machine's shard in the sync directory
+	machineFile = "machine-id" // this machine's shard name, in the config dir
+)
+
+// Sync makes the store write to machine's shard in dir and read the shards
+// of every machine there. Keeping dir in sync between machines (Syncthing,
+// a git repository, ...) is up to the user; as each machine only writes
+// its own file, the copies never conflict.
+func (s *Store) Sync(dir, machine string) *Store {
+	s.syncDir, s.machine = dir, machine
+	return s
+}
+
+// NewID returns a random record ID.
+func NewID() string {
+	return strings.ToLower(rand.Text())
+}
+
+// deriveID gives records saved before IDs existed a stable ID from their
+// date, mode and speed, so every machine derives the same one.
+func deriveID(date time.Time, mode string, wpm float64) string {
+	sum := sha256.Sum256([]byte(date.UTC().Format(time.RFC3339Nano) + "\x00" + mode + "\x00" +
+		strconv.FormatFloat(wpm, 'g', -1, 64)))
+	return hex.EncodeToString(sum[:10])
+}
+
+// machineName returns this machine's shard name, creating it on first use:
+// the host name plus a random suffix, as two laptops may share a name.
+func machineName(dir string) (string, error) {
+	path := filepath.Join(dir, machineFile)
+	if data, err := os.ReadFile(path); err == nil {
+		if name := strings.TrimSpace(string(data)); name != "" {
+			return name, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	host, _ := os.Hostname()
+	host = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, host)
+	if host == "" {
+		host = "machine"
+	}
+	name := host + "-" + NewID()[:6]
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	if err := writeAtomic(path, []byte(name+"\n")); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// scanAll reads the store's own file and, when syncing, every other shard,
+// merged into one timeline without duplicates. Files not migrated yet are
+// read too, as a reader must not move them. Only the store's own
+// unreadable lines are reported; other machines quarantine theirs.
+func (s *Store) scanAll() (scanned, error) {
+	sc, err := s.scan()
+	if err != nil {
+		return sc, err
+	}
+	parts := []scanned{sc}
+
+	var unreadable *CorruptError
+	pending := []string{filepath.Join(s.dir, legacyName)}
+	if s.syncDir != "" {
+		pending = append(pending, filepath.Join(s.dir, fileName))
+	}
+	for _, path := range pending {
+		var old scanned
+		var corrupt *CorruptError
+		if filepath.Ext(path) == ".json" {
+			old, err = scanLegacy(path)
+		} else {
+			old, err = scanFile(path)
+		}
+		if errors.As(err, &corrupt) {
+			unreadable, err = corrupt, nil
+		}
+		if err != nil {
+			return sc, err
+		}
+		parts = append(parts, old)
+	}
+
+	if s.syncDir != "" {
+		shards, err := filepath.Glob(filepath.Join(s.syncDir, "*"+shardExt))
+		if err != nil {
+			return sc, err
+		}
+		for _, path := range shards {
+			if path == s.Path() {
+				continue
+			}
+			other, err := scanFile(path)
+			if err != nil {
+				return sc, err
+			}
+			other.bad = nil
+			parts = append(parts, other)
+		}
+	}
+	all := merge(parts)
+	all.unreadable = unreadable
+	return all, nil
+}
+
+// merge joins scans into one timeline ordered by date, keeping the first
+// record seen for each ID.
+func merge(parts []scanned) scanned {
+	out := scanned{path: parts[0].path, bad: parts[0].bad}
+	seen := make(map[string]bool)
+	for _, p := range parts {
+		for _, r := range p.records {
+			if seen[r.ID] {
+				continue
+			}
+			seen[r.ID] = true
+			out.records = append(out.records, r)
+		}
+	}
+	slices.SortStableFunc(out.records, func(a, b Record) int { return a.Date.Compare(b.Date) })
+	return out
+}
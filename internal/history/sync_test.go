@@ -0,0 +1,108 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyncMergesShards(t *testing.T) {
+	shared := t.TempDir()
+	laptop := New(t.TempDir(), 0).Sync(shared, "laptop-abc123")
+	desktop := New(t.TempDir(), 0).Sync(shared, "desktop-def456")
+
+	day := time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC)
+	if err := laptop.Save(Record{Date: day, Mode: "quote", WPM: 50}); err != nil {
+		t.Fatal(err)
+	}
+	if err := desktop.Save(Record{Date: day.Add(time.Hour), Mode: "quote", WPM: 60}); err != nil {
+		t.Fatal(err)
+	}
+	if err := laptop.Save(Record{Date: day.Add(2 * time.Hour), Mode: "code", WPM: 40}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, s := range map[string]*Store{"laptop": laptop, "desktop": desktop} {
+		records, err := s.Load()
+		if err != nil {
+			t.Fatalf("%s: load: %v", name, err)
+		}
+		if len(records) != 3 || records[0].WPM != 50 || records[1].WPM != 60 || records[2].WPM != 40 {
+			t.Fatalf("%s: expected one timeline of 3 records, got %+v", name, records)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(shared, "laptop-abc123.jsonl")); err != nil {
+		t.Fatalf("expected the laptop shard: %v", err)
+	}
+}
+
+func TestQuarantineNamesTheShard(t *testing.T) {
+	shared := t.TempDir()
+	s := New(t.TempDir(), 0).Sync(shared, "laptop-abc123")
+	if err := os.WriteFile(s.Path(), []byte("not json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(Record{Mode: "quote", WPM: 50}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	q, err := os.ReadFile(filepath.Join(s.dir, quarantineName))
+	if err != nil {
+		t.Fatalf("expected a quarantine file: %v", err)
+	}
+	if !strings.Contains(string(q), `"from":"laptop-abc123.jsonl"`) {
+		t.Fatalf("expected the shard named as the source, got:\n%s", q)
+	}
+}
+
+func TestSyncDeduplicatesByID(t *testing.T) {
+	shared := t.TempDir()
+	s := New(t.TempDir(), 0).Sync(shared, "a")
+	if err := s.Save(Record{ID: "same", WPM: 50}); err != nil {
+		t.Fatal(err)
+	}
+	// The same record copied into another shard, e.g. by a manual merge.
+	data, _ := os.ReadFile(s.Path())
+	if err := os.WriteFile(filepath.Join(shared, "b.jsonl"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	records, err := s.Load()
+	if err != nil || len(records) != 1 {
+		t.Fatalf("expected the duplicate dropped, got %v, %v", records, err)
+	}
+}
+
+func TestSyncMovesLocalHistoryIntoShard(t *testing.T) {
+	local, shared := t.TempDir(), t.TempDir()
+	if err := New(local, 0).Save(Record{Mode: "quote", WPM: 45}); err != nil {
+		t.Fatal(err)
+	}
+	s := New(local, 0).Sync(shared, "laptop")
+	records, _ := s.Load()
+	if len(records) != 1 {
+		t.Fatalf("expected the local record before migrating, got %+v", records)
+	}
+	if err := s.Save(Record{Mode: "quote", WPM: 55}); err != nil {
+		t.Fatal(err)
+	}
+	records, _ = s.Load()
+	if len(records) != 2 {
+		t.Fatalf("expected both records after migrating, got %+v", records)
+	}
+	if _, err := os.Stat(filepath.Join(local, fileName+".synced")); err != nil {
+		t.Fatalf("expected the local history kept as .synced: %v", err)
+	}
+}
+
+func TestDerivedIDsAreStable(t *testing.T) {
+	line := []byte(`{"date":"2026-01-02T03:04:05Z","mode":"quote","wpm":61.5}`)
+	a, err := decodeRecord(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := decodeRecord(line)
+	if a.ID == "" || a.ID != b.ID {
+		t.Fatalf("expected the same derived ID, got %q and %q", a.ID, b.ID)
+	}
+}
@@ -0,0 +1,321 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats lists the export formats.
+func Formats() []string {
+	return []string{"csv", "json", "jsonl"}
+}
+
+// csvColumns is the header of a CSV export. Import matches columns by
+// name, so the order may change; remove columns only with care.
+var csvColumns = []string{
+	"id", "date", "mode", "test_type", "word_count", "quote_length", "time_limit_sec", "language", "modifiers",
+	"wpm", "raw_wpm", "accuracy", "errors", "raw_accuracy", "corrected_errors",
+	"uncorrected_errors", "backspaces", "consistency", "burst_wpm", "time_taken_sec",
+	"completed", "tier",
+}
+
+// Export writes records in format ("csv", "json" or "jsonl").
+func Export(w io.Writer, records []Record, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if records == nil {
+			records = []Record{}
+		}
+		return enc.Encode(records)
+	case "jsonl":
+		enc := json.NewEncoder(w)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvColumns); err != nil {
+			return err
+		}
+		for _, r := range records {
+			if err := cw.Write(csvRow(r)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(Formats(), ", "))
+	}
+}
+
+func csvRow(r Record) []string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return []string{
+		r.ID, r.Date.Format(time.RFC3339Nano), r.Mode, r.TestType, strconv.Itoa(r.WordCount), r.QuoteLength, f(r.TimeLimit),
+		r.Language, strings.Join(r.Modifiers, "+"),
+		f(r.WPM), f(r.RawWPM), f(r.Accuracy), strconv.Itoa(r.Errors), f(r.RawAccuracy),
+		strconv.Itoa(r.CorrectedErrors), strconv.Itoa(r.UncorrectedErrors), strconv.Itoa(r.Backspaces),
+		f(r.Consistency), f(r.BurstWPM), f(r.TimeTaken), strconv.FormatBool(r.Completed), r.Tier,
+	}
+}
+
+// ReadRecords parses an export: a JSON array, JSON Lines, a typr CSV or a
+// monkeytype CSV, told apart by their content. JSON records go through the
+// schema migrations like the history file does.
+func ReadRecords(r io.Reader) ([]Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff")) // spreadsheet BOM
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) == 0:
+		return nil, nil
+	case trimmed[0] == '[':
+		sc, err := scanArray("input", data)
+		if err != nil {
+			return nil, err
+		}
+		return sc.records, sc.strict()
+	case trimmed[0] == '{':
+		sc := scanned{path: "input"}
+		for n, line := range bytes.Split(data, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				sc.add(n+1, line)
+			}
+		}
+		return sc.records, sc.strict()
+	default:
+		return readCSV(data)
+	}
+}
+
+// strict reports the first line that was not loaded, so an import
+// either takes every record or none.
+func (sc scanned) strict() error {
+	if len(sc.bad) > 0 {
+		return fmt.Errorf("line %d: %w", sc.bad[0].n, sc.bad[0].err)
+	}
+	if len(sc.newer) > 0 {
+		return errNewerVersion
+	}
+	return nil
+}
+
+// readCSV parses a typr or monkeytype CSV export.
+func readCSV(data []byte) ([]Record, error) {
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	col := make(map[string]int, len(rows[0]))
+	for i, name := range rows[0] {
+		col[strings.TrimSpace(name)] = i
+	}
+	convert := typrRow
+	if isMonkeytype(col) {
+		convert = monkeytypeRow
+	} else if _, ok := col["date"]; !ok {
+		return nil, errors.New("unrecognised CSV: want a typr or monkeytype export")
+	}
+	// Exports from before schema v5 have no quote_length column; their
+	// quote tests were random words.
+	_, quotes := col["quote_length"]
+
+	records := make([]Record, 0, len(rows)-1)
+	for n, row := range rows[1:] {
+		get := func(name string) string {
+			if i, ok := col[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		r, err := convert(get)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+2, err)
+		}
+		if !quotes && r.Mode == "quote" {
+			r.Mode = "words"
+		}
+		r.Version = CurrentVersion
+		if r.ID == "" {
+			r.ID = deriveID(r.Date, r.Mode, r.WPM)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// fieldParser collects the first conversion error of a row.
+type fieldParser struct {
+	get func(string) string
+	err error
+}
+
+func (p *fieldParser) float(name string) float64 {
+	s := p.get(name)
+	if s == "" || p.err != nil {
+		return 0
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		p.err = fmt.Errorf("%s: %w", name, err)
+	}
+	return v
+}
+
+func (p *fieldParser) int(name string) int {
+	return int(p.float(name))
+}
+
+func (p *fieldParser) bool(name string) bool {
+	s := p.get(name)
+	if s == "" || p.err != nil {
+		return false
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		p.err = fmt.Errorf("%s: %w", name, err)
+	}
+	return v
+}
+
+func typrRow(get func(string) string) (Record, error) {
+	p := &fieldParser{get: get}
+	date, err := time.Parse(time.RFC3339Nano, get("date"))
+	if err != nil {
+		return Record{}, fmt.Errorf("date: %w", err)
+	}
+	r := Record{
+		ID:        get("id"),
+		Date:      date,
+		Mode:      get("mode"),
+		TestType:  get("test_type"),
+		WordCount: p.int("word_count"),
+		TimeLimit: p.float("time_limit_sec"),
+		Language:  get("language"),
+		WPM:       p.float("wpm"),
+		RawWPM:    p.float("raw_wpm"),
+		Accuracy:  p.float("accuracy"),
+		Errors:    p.int("errors"),
+
+		RawAccuracy:       p.float("raw_accuracy"),
+		CorrectedErrors:   p.int("corrected_errors"),
+		UncorrectedErrors: p.int("uncorrected_errors"),
+		Backspaces:        p.int("backspaces"),
+		Consistency:       p.float("consistency"),
+		BurstWPM:          p.float("burst_wpm"),
+
+		TimeTaken: p.float("time_taken_sec"),
+		Completed: p.bool("completed"),
+		Tier:      get("tier"),
+	}
+	r.QuoteLength = get("quote_length")
+	if mods := get("modifiers"); mods != "" {
+		r.Modifiers = strings.Split(mods, "+")
+	}
+	if r.TestType == "" {
+		r.TestType = "words"
+	}
+	return r, p.err
+}
+
+// dedupKey identifies a record for imports: the same test has the same
+// date, mode and speed wherever it was exported from.
+type dedupKey struct {
+	date int64
+	mode string
+	wpm  float64
+}
+
+func keyOf(r Record) dedupKey {
+	return dedupKey{date: r.Date.UnixMilli(), mode: r.Mode, wpm: r.WPM}
+}
+
+// Import adds records that are not in the history yet, matching on date,
+// mode and WPM, and returns how many were added. The history is kept in
+// date order and the personal bests are updated.
+func (s *Store) Import(records []Record) (int, error) {
+	unlock, err := s.lock(true)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	warning, err := s.migrate()
+	if err != nil {
+		return 0, err
+	}
+	sc, err := s.scan()
+	if err != nil {
+		return 0, err
+	}
+	all, err := s.scanAll()
+	if err != nil {
+		return 0, err
+	}
+	seen := make(map[dedupKey]bool, len(all.records))
+	ids := make(map[string]bool, len(all.records))
+	for _, r := range all.records {
+		seen[keyOf(r)] = true
+		ids[r.ID] = true
+	}
+	bests, err := s.readBests()
+	if err != nil {
+		return 0, err
+	}
+
+	merged := sc.records
+	added := 0
+	for _, r := range records {
+		if seen[keyOf(r)] || ids[r.ID] {
+			continue
+		}
+		seen[keyOf(r)] = true
+		ids[r.ID] = true
+		if r.Tier == "" {
+			r.Tier = Tier(r.WPM)
+		}
+		merged = append(merged, r)
+		improveBest(bests, r)
+		added++
+	}
+	if added == 0 {
+		return 0, warning
+	}
+	slices.SortStableFunc(merged, func(a, b Record) int { return a.Date.Compare(b.Date) })
+	if s.retention > 0 && len(merged) > s.retention {
+		merged = merged[len(merged)-s.retention:]
+	}
+
+	if err := s.quarantine(sc.bad); err != nil {
+		return 0, err
+	}
+	if err := s.rewrite(merged, sc.newer); err != nil {
+		return 0, err
+	}
+	if err := s.writeBests(bests); err != nil {
+		return 0, err
+	}
+	return added, warning
+}
@@ -0,0 +1,102 @@
+package history
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	records := []Record{
+		{Version: CurrentVersion, Date: time.Date(2026, 5, 1, 9, 30, 0, 123456789, time.UTC), Mode: "quote", TestType: "words",
+			WordCount: 30, Language: "english", Modifiers: []string{"punctuation"}, WPM: 61.25, Accuracy: 97.5, Completed: true, Tier: "Fast"},
+		{Version: CurrentVersion, Date: time.Date(2026, 5, 2, 9, 30, 0, 0, time.UTC), Mode: "code", TestType: "time",
+			TimeLimit: 60, Language: "english", WPM: 44, Consistency: 71.2, Tier: "Average",
+			Samples: []Sample{{At: 1, WPM: 40, RawWPM: 48, Errors: 1}, {At: 2, WPM: 44, RawWPM: 50}}},
+	}
+	for _, format := range Formats() {
+		var buf bytes.Buffer
+		if err := Export(&buf, records, format); err != nil {
+			t.Fatalf("%s: export: %v", format, err)
+		}
+		got, err := ReadRecords(&buf)
+		if err != nil {
+			t.Fatalf("%s: read: %v", format, err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("%s: expected 2 records, got %d", format, len(got))
+		}
+		for i := range got {
+			if keyOf(got[i]) != keyOf(records[i]) || got[i].Key() != records[i].Key() || got[i].Accuracy != records[i].Accuracy {
+				t.Fatalf("%s: record %d changed:\n got  %+v\n want %+v", format, i, got[i], records[i])
+			}
+			// CSV has one row per test, so only JSON keeps the speed series.
+			if format != "csv" && !slices.Equal(got[i].Samples, records[i].Samples) {
+				t.Fatalf("%s: record %d samples changed: got %+v", format, i, got[i].Samples)
+			}
+		}
+	}
+}
+
+func TestReadLegacyCSV(t *testing.T) {
+	csv := `id,date,mode,test_type,word_count,wpm,completed
+a,2026-05-01T09:30:00Z,quote,words,30,61.5,true
+`
+	records, err := ReadRecords(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(records) != 1 || records[0].Mode != "words" || records[0].Key().WordCount != 30 {
+		t.Fatalf("expected the quote test as a 30-word test, got %+v", records)
+	}
+}
+
+func TestReadMonkeytypeCSV(t *testing.T) {
+	csv := `_id,isPb,wpm,acc,rawWpm,consistency,charStats,mode,mode2,quoteLength,restartCount,testDuration,afkDuration,incompleteTestSeconds,punctuation,numbers,language,funbox,difficulty,lazyMode,blindMode,bailedOut,tags,timestamp
+abc,true,85.2,96.5,90.1,78.3,250;5;2;1,time,30,-1,0,30,0,0,true,false,english,none,normal,false,false,false,,1714557000000
+def,false,70,99,71,80,120;1;0;0,words,25,-1,1,21.4,0,0,false,false,english_1k,none,normal,false,false,false,,1714560000000
+`
+	records, err := ReadRecords(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	r := records[0]
+	if r.Mode != MonkeytypeMode || r.TestType != "time" || r.TimeLimit != 30 || r.WPM != 85.2 || r.Errors != 8 {
+		t.Fatalf("unexpected time record %+v", r)
+	}
+	if len(r.Modifiers) != 1 || r.Modifiers[0] != "punctuation" || !r.Date.Equal(time.UnixMilli(1714557000000)) {
+		t.Fatalf("unexpected modifiers or date %+v", r)
+	}
+	if w := records[1]; w.TestType != "words" || w.WordCount != 25 || w.Language != "english_1k" || !w.Completed {
+		t.Fatalf("unexpected word record %+v", w)
+	}
+}
+
+func TestImportDeduplicates(t *testing.T) {
+	s := New(t.TempDir(), 0)
+	day := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	if err := s.Save(Record{Date: day.Add(time.Hour), Mode: "quote", TestType: "words", WordCount: 30, WPM: 50, Completed: true}); err != nil {
+		t.Fatal(err)
+	}
+	batch := []Record{
+		{Date: day.Add(time.Hour), Mode: "quote", TestType: "words", WordCount: 30, WPM: 50, Completed: true}, // already saved
+		{Date: day, Mode: "quote", TestType: "words", WordCount: 30, WPM: 65, Completed: true},
+		{Date: day, Mode: "quote", TestType: "words", WordCount: 30, WPM: 65, Completed: true}, // repeated in the file
+	}
+	added, err := s.Import(batch)
+	if err != nil || added != 1 {
+		t.Fatalf("expected 1 record added, got %d, %v", added, err)
+	}
+	records, _ := s.Load()
+	if len(records) != 2 || records[0].WPM != 65 {
+		t.Fatalf("expected the import sorted before the saved record, got %+v", records)
+	}
+	if best, _ := s.PersonalBest(records[0].Key()); best.WPM != 65 {
+		t.Fatalf("expected the import to set the PB, got %+v", best)
+	}
+}
@@ -0,0 +1,206 @@
+// Package stats summarises typing history: distributions, best and worst
+// runs, and the speed trend over time.
+package stats
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"terminal-wpm/internal/history"
+)
+
+// Filter selects records. Zero fields match everything, except that
+// practice drills are only included when Mode asks for them.
+type Filter struct {
+	Mode      string
+	Since     time.Time // inclusive
+	Until     time.Time // exclusive
+	WordCount int
+	TimeLimit time.Duration
+}
+
+// Match reports whether r passes the filter.
+func (f Filter) Match(r history.Record) bool {
+	switch {
+	case f.Mode == "" && r.Mode == history.PracticeMode:
+		return false
+	case f.Mode != "" && r.Mode != f.Mode:
+		return false
+	case !f.Since.IsZero() && r.Date.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !r.Date.Before(f.Until):
+		return false
+	case f.WordCount > 0 && (r.TestType == "time" || r.WordCount != f.WordCount):
+		return false
+	case f.TimeLimit > 0 && (r.TestType != "time" || r.TimeLimit != f.TimeLimit.Seconds()):
+		return false
+	}
+	return true
+}
+
+// Apply returns the records that pass the filter, in their original order.
+func (f Filter) Apply(records []history.Record) []history.Record {
+	var out []history.Record
+	for _, r := range records {
+		if f.Match(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Distribution describes a set of values.
+type Distribution struct {
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	P10    float64 `json:"p10"`
+	P25    float64 `json:"p25"`
+	P75    float64 `json:"p75"`
+	P90    float64 `json:"p90"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// Run is one test picked out of the history.
+type Run struct {
+	Date     time.Time `json:"date"`
+	Mode     string    `json:"mode"`
+	Test     string    `json:"test"`
+	WPM      float64   `json:"wpm"`
+	Accuracy float64   `json:"accuracy"`
+}
+
+// Trend is the least-squares line through WPM over time.
+type Trend struct {
+	WPMPerWeek float64   `json:"wpm_per_week"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+}
+
+// Summary is everything `typr stats` reports.
+type Summary struct {
+	Tests       int          `json:"tests"`
+	TimeTyping  float64      `json:"time_typing_sec"`
+	WPM         Distribution `json:"wpm"`
+	Accuracy    Distribution `json:"accuracy"`
+	RawAccuracy float64      `json:"raw_accuracy,omitempty"` // mean over records that have it
+	Consistency float64      `json:"consistency,omitempty"`  // mean over records that have it
+	Best        *Run         `json:"best,omitempty"`
+	Worst       *Run         `json:"worst,omitempty"`
+	Trend       *Trend       `json:"trend,omitempty"` // nil until tests span some time
+}
+
+// Summarize computes the summary of records.
+func Summarize(records []history.Record) Summary {
+	s := Summary{Tests: len(records)}
+	if len(records) == 0 {
+		return s
+	}
+
+	wpm := make([]float64, len(records))
+	acc := make([]float64, len(records))
+	var rawAcc, cons []float64
+	best, worst := records[0], records[0]
+	for i, r := range records {
+		wpm[i], acc[i] = r.WPM, r.Accuracy
+		s.TimeTyping += r.TimeTaken
+		if r.RawAccuracy > 0 { // older records predate raw accuracy
+			rawAcc = append(rawAcc, r.RawAccuracy)
+		}
+		if r.Consistency > 0 {
+			cons = append(cons, r.Consistency)
+		}
+		if r.WPM > best.WPM {
+			best = r
+		}
+		if r.WPM < worst.WPM {
+			worst = r
+		}
+	}
+	s.WPM = Describe(wpm)
+	s.Accuracy = Describe(acc)
+	s.RawAccuracy = mean(rawAcc)
+	s.Consistency = mean(cons)
+	s.Best, s.Worst = runOf(best), runOf(worst)
+	s.Trend = TrendOf(records)
+	return s
+}
+
+// Describe computes the distribution of values.
+func Describe(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+	sorted := slices.Sorted(slices.Values(values))
+	return Distribution{
+		Mean:   mean(sorted),
+		Median: Percentile(sorted, 50),
+		P10:    Percentile(sorted, 10),
+		P25:    Percentile(sorted, 25),
+		P75:    Percentile(sorted, 75),
+		P90:    Percentile(sorted, 90),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// Percentile returns the p-th percentile of sorted values, interpolating
+// linearly between the closest ranks.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := min(lo+1, len(sorted)-1)
+	return sorted[lo] + (rank-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+// TrendOf fits WPM against time by least squares. It returns nil when the
+// records do not span at least an hour, as the slope would be noise.
+func TrendOf(records []history.Record) *Trend {
+	if len(records) < 2 {
+		return nil
+	}
+	from, to := records[0].Date, records[0].Date
+	for _, r := range records {
+		if r.Date.Before(from) {
+			from = r.Date
+		}
+		if r.Date.After(to) {
+			to = r.Date
+		}
+	}
+	if to.Sub(from) < time.Hour {
+		return nil
+	}
+
+	const week = 7 * 24 * time.Hour
+	var sx, sy, sxx, sxy float64
+	for _, r := range records {
+		x := float64(r.Date.Sub(from)) / float64(week)
+		sx += x
+		sy += r.WPM
+		sxx += x * x
+		sxy += x * r.WPM
+	}
+	n := float64(len(records))
+	slope := (n*sxy - sx*sy) / (n*sxx - sx*sx)
+	return &Trend{WPMPerWeek: slope, From: from, To: to}
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func runOf(r history.Record) *Run {
+	return &Run{Date: r.Date, Mode: r.Mode, Test: r.Label(), WPM: r.WPM, Accuracy: r.Accuracy}
+}
@@ -0,0 +1,82 @@
+package stats
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"terminal-wpm/internal/history"
+)
+
+func TestPercentileInterpolates(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40}
+	cases := map[float64]float64{0: 10, 50: 25, 100: 40, 25: 17.5}
+	for p, want := range cases {
+		if got := Percentile(sorted, p); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("p%.0f: expected %.2f, got %.2f", p, want, got)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	d := Describe([]float64{50, 10, 30, 20, 40})
+	if d.Mean != 30 || d.Median != 30 || d.Min != 10 || d.Max != 50 {
+		t.Fatalf("unexpected distribution %+v", d)
+	}
+}
+
+func TestTrendSlopePerWeek(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var records []history.Record
+	for week := range 4 {
+		records = append(records, history.Record{Date: start.AddDate(0, 0, 7*week), WPM: 40 + 2*float64(week)})
+	}
+	trend := TrendOf(records)
+	if trend == nil || math.Abs(trend.WPMPerWeek-2) > 1e-9 {
+		t.Fatalf("expected +2 WPM/week, got %+v", trend)
+	}
+	if TrendOf(records[:1]) != nil {
+		t.Fatalf("expected no trend from a single test")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	records := []history.Record{
+		{Date: day, Mode: "quote", TestType: "words", WordCount: 30},
+		{Date: day.AddDate(0, 0, 1), Mode: "code", TestType: "words", WordCount: 30},
+		{Date: day.AddDate(0, 0, 2), Mode: "quote", TestType: "time", TimeLimit: 60},
+		{Date: day.AddDate(0, 0, 3), Mode: history.PracticeMode, TestType: "words", WordCount: 30},
+	}
+	cases := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"all but practice", Filter{}, 3},
+		{"practice on request", Filter{Mode: history.PracticeMode}, 1},
+		{"mode", Filter{Mode: "quote"}, 2},
+		{"words", Filter{WordCount: 30}, 2},
+		{"time", Filter{TimeLimit: time.Minute}, 1},
+		{"range", Filter{Since: day.AddDate(0, 0, 1), Until: day.AddDate(0, 0, 2)}, 1},
+	}
+	for _, tc := range cases {
+		if got := len(tc.filter.Apply(records)); got != tc.want {
+			t.Fatalf("%s: expected %d records, got %d", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestSummarizePicksBestAndWorst(t *testing.T) {
+	s := Summarize([]history.Record{
+		{Mode: "quote", WPM: 40, TimeTaken: 30},
+		{Mode: "quote", WPM: 70, TimeTaken: 30},
+		{Mode: "code", WPM: 20, TimeTaken: 60},
+	})
+	if s.Tests != 3 || s.TimeTyping != 120 {
+		t.Fatalf("unexpected totals %+v", s)
+	}
+	if s.Best.WPM != 70 || s.Worst.WPM != 20 || s.Worst.Mode != "code" {
+		t.Fatalf("unexpected best/worst %+v / %+v", s.Best, s.Worst)
+	}
+}
@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"slices"
+	"time"
+
+	"terminal-wpm/internal/config"
+	"terminal-wpm/internal/history"
+)
+
+// Calendar assigns tests to practice days. A day starts at Rollover
+// o'clock in Location, so a session shortly after midnight still counts
+// for the evening before.
+type Calendar struct {
+	Location *time.Location // nil is the system zone
+	Rollover int            // hour a new day starts, 0-23
+}
+
+// CalendarOf returns the calendar set up in settings.
+func CalendarOf(settings config.Settings) Calendar {
+	return Calendar{Location: settings.Location(), Rollover: settings.DayRollover}
+}
+
+// Day returns midnight at the start of the practice day t falls on.
+func (c Calendar) Day(t time.Time) time.Time {
+	loc := c.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc).Add(-time.Duration(c.Rollover) * time.Hour)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// DayTotal sums the tests of one practice day.
+type DayTotal struct {
+	Tests   int     `json:"tests"`
+	Minutes float64 `json:"minutes"`
+	WPM     float64 `json:"wpm"` // mean over the day's tests
+}
+
+// Met reports whether the day reaches every target set in goal.
+func (d DayTotal) Met(goal config.Goal) bool {
+	return d.Tests > 0 && d.Tests >= goal.Tests && d.Minutes >= goal.Minutes && d.WPM >= goal.WPM
+}
+
+// Streak is the state of the daily habit: today's progress and how many
+// days in a row met the goal.
+type Streak struct {
+	Today   DayTotal    `json:"today"`
+	Goal    config.Goal `json:"goal"`
+	GoalMet bool        `json:"goal_met"` // today's goal
+	Current int         `json:"current"`  // days in a row ending today, or yesterday while today is open
+	Longest int         `json:"longest"`
+}
+
+// Streaks works out the streak at now from the records' dates. Like
+// achievements, only finished tests count: abandoned runs and practice
+// drills would meet a tests goal by restarting and drag down the WPM.
+func Streaks(records []history.Record, goal config.Goal, cal Calendar, now time.Time) Streak {
+	totals := make(map[time.Time]DayTotal)
+	for _, r := range records {
+		if !r.Completed || r.Mode == history.PracticeMode {
+			continue
+		}
+		day := cal.Day(r.Date)
+		t := totals[day]
+		t.WPM = (t.WPM*float64(t.Tests) + r.WPM) / float64(t.Tests+1)
+		t.Tests++
+		t.Minutes += r.TimeTaken / 60
+		totals[day] = t
+	}
+
+	var met []time.Time
+	for day, t := range totals {
+		if t.Met(goal) {
+			met = append(met, day)
+		}
+	}
+	slices.SortFunc(met, time.Time.Compare)
+
+	today := cal.Day(now)
+	s := Streak{Today: totals[today], Goal: goal}
+	s.GoalMet = s.Today.Met(goal)
+
+	run := 0
+	for i, day := range met {
+		if i > 0 && met[i-1].AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		s.Longest = max(s.Longest, run)
+		// The streak is alive if it reaches today, or yesterday while
+		// today's goal is still open.
+		if day.Equal(today) || (day.Equal(today.AddDate(0, 0, -1)) && !s.GoalMet) {
+			s.Current = run
+		}
+	}
+	return s
+}
@@ -0,0 +1,56 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"terminal-wpm/internal/config"
+	"terminal-wpm/internal/history"
+)
+
+func TestCalendarRollover(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*3600)
+	cal := Calendar{Location: tokyo, Rollover: 4}
+	// 01:30 in Tokyo on March 11 is still March 10's practice day.
+	late := time.Date(2026, 3, 10, 16, 30, 0, 0, time.UTC)
+	if got := cal.Day(late); !got.Equal(time.Date(2026, 3, 10, 0, 0, 0, 0, tokyo)) {
+		t.Fatalf("expected March 10 in Tokyo, got %s", got)
+	}
+	if got := cal.Day(late.Add(3 * time.Hour)); got.Day() != 11 {
+		t.Fatalf("expected March 11 after the rollover, got %s", got)
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	cal := Calendar{Location: time.UTC}
+	day := func(d, hour int) time.Time { return time.Date(2026, 3, d, hour, 0, 0, 0, time.UTC) }
+	var records []history.Record
+	for _, d := range []int{1, 2, 3, 4, 7, 8} {
+		records = append(records, history.Record{Date: day(d, 12), WPM: 50, TimeTaken: 60, Completed: true})
+	}
+
+	s := Streaks(records, config.Goal{}, cal, day(9, 10))
+	if s.Current != 2 || s.Longest != 4 || s.GoalMet {
+		t.Fatalf("expected current 2 (today open), longest 4, got %+v", s)
+	}
+	if s := Streaks(records, config.Goal{}, cal, day(10, 10)); s.Current != 0 {
+		t.Fatalf("expected the streak to break after a missed day, got %+v", s)
+	}
+
+	// Two minutes a day is only met on the 8th, which has a second test.
+	records = append(records, history.Record{Date: day(8, 13), WPM: 70, TimeTaken: 60, Completed: true})
+	s = Streaks(records, config.Goal{Minutes: 2}, cal, day(8, 20))
+	if !s.GoalMet || s.Current != 1 || s.Longest != 1 || s.Today.Tests != 2 || s.Today.WPM != 60 {
+		t.Fatalf("expected only today to meet the goal, got %+v", s)
+	}
+
+	// Abandoned runs and drills neither add tests nor pull the mean down.
+	records = append(records,
+		history.Record{Date: day(8, 14), WPM: 5, TimeTaken: 2},
+		history.Record{Date: day(8, 15), WPM: 20, TimeTaken: 60, Completed: true, Mode: history.PracticeMode},
+	)
+	s = Streaks(records, config.Goal{Tests: 3}, cal, day(8, 20))
+	if s.GoalMet || s.Today.Tests != 2 || s.Today.WPM != 60 || s.Today.Minutes != 2 {
+		t.Fatalf("expected only the two finished tests to count, got %+v", s)
+	}
+}